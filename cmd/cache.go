@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/iamfat/docker-genee/internal/registry"
+	"github.com/spf13/cobra"
+)
+
+var pruneAll bool
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "管理本地元数据缓存",
+	Long:  `管理按摘要缓存在本地的manifest和config blob`,
+}
+
+var cacheInfoCmd = &cobra.Command{
+	Use:   "info",
+	Short: "查看缓存信息",
+	Args:  cobra.NoArgs,
+	RunE:  runCacheInfo,
+}
+
+var cachePruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "清理缓存",
+	Long:  `按最近访问时间清理缓存，直到不超过容量上限；使用 --all 清空全部缓存`,
+	Args:  cobra.NoArgs,
+	RunE:  runCachePrune,
+}
+
+func init() {
+	rootCmd.AddCommand(cacheCmd)
+	geneeCmd.AddCommand(cacheCmd)
+
+	cacheCmd.AddCommand(cacheInfoCmd)
+	cacheCmd.AddCommand(cachePruneCmd)
+
+	cachePruneCmd.Flags().BoolVar(&pruneAll, "all", false, "清空全部缓存")
+}
+
+// newCache 创建位于配置目录下的缓存，容量上限可以在镜像源配置中设置
+func newCache() *registry.Cache {
+	return registry.NewCache(filepath.Join(configDir, "cache"), cacheMaxSize)
+}
+
+func runCacheInfo(cmd *cobra.Command, args []string) error {
+	info, err := newCache().Info()
+	if err != nil {
+		return fmt.Errorf("读取缓存信息失败: %v", err)
+	}
+
+	fmt.Printf("缓存目录: %s\n", info.Dir)
+	fmt.Printf("缓存条目: %d\n", info.Entries)
+	fmt.Printf("缓存大小: %s / %s\n", registry.FormatSize(info.Size), registry.FormatSize(info.MaxSize))
+	return nil
+}
+
+func runCachePrune(cmd *cobra.Command, args []string) error {
+	cache := newCache()
+
+	limit := cacheMaxSize
+	if pruneAll {
+		limit = 0
+	}
+
+	freed, err := cache.Prune(limit)
+	if err != nil {
+		return fmt.Errorf("清理缓存失败: %v", err)
+	}
+
+	fmt.Printf("已释放 %s\n", registry.FormatSize(freed))
+	return nil
+}
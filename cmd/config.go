@@ -0,0 +1,212 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/iamfat/docker-genee/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	profileURL          string
+	profileScheme       string
+	profileCACert       string
+	profilePlatform     string
+	profileConcurrency  int
+	profileCacheSize    string
+	profileDisableCache bool
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "管理镜像源配置",
+	Long: `管理保存在 ~/.docker-genee/config.yaml 中的镜像源配置（profile）
+
+每个配置包含镜像源地址、协议、CA证书、默认平台、并发数量和缓存设置，
+使用 --profile 参数或 DOCKER_GENEE_PROFILE 环境变量临时切换配置。
+
+示例:
+  docker genee config add staging --url registry.staging.genee.cn --platform linux/arm64
+  docker genee config add mirror --url localhost:5000 --scheme http --disable-cache
+  docker genee config use staging
+  docker genee images --profile mirror`,
+}
+
+var configListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "列出镜像源配置",
+	Args:    cobra.NoArgs,
+	RunE:    runConfigList,
+}
+
+var configAddCmd = &cobra.Command{
+	Use:   "add <name>",
+	Short: "添加镜像源配置",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runConfigAdd,
+}
+
+var configUseCmd = &cobra.Command{
+	Use:   "use <name>",
+	Short: "设置当前使用的镜像源配置",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runConfigUse,
+}
+
+var configRemoveCmd = &cobra.Command{
+	Use:     "remove <name>",
+	Aliases: []string{"rm"},
+	Short:   "删除镜像源配置",
+	Args:    cobra.ExactArgs(1),
+	RunE:    runConfigRemove,
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	geneeCmd.AddCommand(configCmd)
+
+	configCmd.AddCommand(configListCmd)
+	configCmd.AddCommand(configAddCmd)
+	configCmd.AddCommand(configUseCmd)
+	configCmd.AddCommand(configRemoveCmd)
+
+	configAddCmd.Flags().StringVar(&profileURL, "url", "", "镜像源地址 (如: docker.genee.cn, localhost:5000)")
+	configAddCmd.Flags().StringVar(&profileScheme, "scheme", "", "访问镜像源使用的协议: https 或 http (默认: https)")
+	configAddCmd.Flags().StringVar(&profileCACert, "ca", "", "校验镜像源证书使用的CA证书文件")
+	configAddCmd.Flags().StringVar(&profilePlatform, "platform", "", "默认的平台过滤条件 (如: linux/amd64)")
+	configAddCmd.Flags().IntVar(&profileConcurrency, "concurrency", 0, "默认的并发请求数量")
+	configAddCmd.Flags().StringVar(&profileCacheSize, "cache-size", "", "元数据缓存容量上限 (如: 512MB)")
+	configAddCmd.Flags().BoolVar(&profileDisableCache, "disable-cache", false, "在该配置中禁用本地元数据缓存")
+	configAddCmd.MarkFlagRequired("url")
+}
+
+// configPath 返回配置文件的路径
+func configPath() string {
+	return filepath.Join(configDir, config.FileName)
+}
+
+func runConfigList(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load(configPath())
+	if err != nil {
+		return err
+	}
+
+	if len(cfg.Profiles) == 0 {
+		fmt.Println("没有镜像源配置，使用 'docker genee config add' 添加")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "NAME\tURL\tSCHEME\tCA\tPLATFORM\tCONCURRENCY\tCACHE")
+	for _, name := range cfg.Names() {
+		profile, _ := cfg.Profile(name)
+
+		display := name
+		if name == cfg.Current {
+			display += " *"
+		}
+		scheme := profile.Scheme
+		if scheme == "" {
+			scheme = "https"
+		}
+		concurrency := "-"
+		if profile.Concurrency > 0 {
+			concurrency = strconv.Itoa(profile.Concurrency)
+		}
+		cache := "启用"
+		if !profile.CacheEnabled() {
+			cache = "禁用"
+		} else if profile.Cache.MaxSize != "" {
+			cache = profile.Cache.MaxSize
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			display,
+			profile.URL,
+			scheme,
+			valueOrDash(profile.CACert),
+			valueOrDash(profile.Platform),
+			concurrency,
+			cache)
+	}
+	return w.Flush()
+}
+
+func runConfigAdd(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load(configPath())
+	if err != nil {
+		return err
+	}
+
+	profile := &config.Profile{
+		URL:         profileURL,
+		Scheme:      profileScheme,
+		Platform:    profilePlatform,
+		Concurrency: profileConcurrency,
+		Cache: config.CacheConfig{
+			MaxSize: profileCacheSize,
+		},
+	}
+	if profileCACert != "" {
+		// 保存绝对路径，避免在其他目录下执行时找不到证书
+		if profile.CACert, err = filepath.Abs(profileCACert); err != nil {
+			return err
+		}
+	}
+	if profileDisableCache {
+		enabled := false
+		profile.Cache.Enabled = &enabled
+	}
+
+	if err := cfg.Add(args[0], profile); err != nil {
+		return err
+	}
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("保存配置失败: %v", err)
+	}
+
+	fmt.Printf("已添加配置 %s\n", args[0])
+	if cfg.Current == args[0] {
+		fmt.Printf("当前使用配置 %s\n", args[0])
+	}
+	return nil
+}
+
+func runConfigUse(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load(configPath())
+	if err != nil {
+		return err
+	}
+
+	if err := cfg.Use(args[0]); err != nil {
+		return err
+	}
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("保存配置失败: %v", err)
+	}
+
+	fmt.Printf("当前使用配置 %s\n", args[0])
+	return nil
+}
+
+func runConfigRemove(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load(configPath())
+	if err != nil {
+		return err
+	}
+
+	if err := cfg.Remove(args[0]); err != nil {
+		return err
+	}
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("保存配置失败: %v", err)
+	}
+
+	fmt.Printf("已删除配置 %s\n", args[0])
+	return nil
+}
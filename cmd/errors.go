@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/iamfat/docker-genee/internal/registry"
+)
+
+// registryError 包装registry返回的错误，并根据错误码给出处理建议
+func registryError(message string, err error) error {
+	hint := ""
+	switch registry.ErrorCodeOf(err) {
+	case registry.ErrorCodeUnauthorized:
+		hint = "请使用 'docker genee login' 重新登录"
+	case registry.ErrorCodeDenied:
+		hint = "当前账号没有访问权限，请检查账号权限或使用其他账号登录"
+	case registry.ErrorCodeNameUnknown:
+		hint = "请确认仓库名称是否正确，可以使用 'docker genee search' 查找仓库"
+	case registry.ErrorCodeManifestUnknown:
+		hint = "请确认标签或摘要是否存在，可以使用 'docker genee tags <仓库>' 查看所有标签"
+	case registry.ErrorCodeTooManyRequests:
+		hint = "请稍后重试，或使用 --concurrency 降低并发请求数量"
+	}
+
+	if hint == "" {
+		return fmt.Errorf("%s: %w", message, err)
+	}
+	return fmt.Errorf("%s: %w\n%s", message, err, hint)
+}
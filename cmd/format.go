@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+	"unicode"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// 支持的输出格式，其他值按Go模板处理
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatYAML  = "yaml"
+
+	// tablePrefix 以 "table " 开头的模板按表格输出，并根据模板中的字段输出表头
+	tablePrefix = "table "
+)
+
+// templateEscapes 与docker一致，模板中的 \t 和 \n 转换为制表符和换行
+var templateEscapes = strings.NewReplacer(`\t`, "\t", `\n`, "\n")
+
+// outputOptions 列表类命令共用的输出参数
+type outputOptions struct {
+	format  string
+	noTrunc bool
+	quiet   bool
+}
+
+// addOutputFlags 为命令添加 --format、--no-trunc 和 --quiet 参数
+func addOutputFlags(cmd *cobra.Command, opts *outputOptions) {
+	cmd.Flags().StringVar(&opts.format, "format", formatTable, "输出格式: table、json、yaml 或Go模板 (如: '{{.Repository}}:{{.Tag}}'、'table {{.Repository}}\\t{{.Tag}}')")
+	cmd.Flags().BoolVar(&opts.noTrunc, "no-trunc", false, "不截断输出")
+	cmd.Flags().BoolVarP(&opts.quiet, "quiet", "q", false, "只输出镜像名称，不显示进度")
+}
+
+// isTable 判断是否使用表格输出
+func (o *outputOptions) isTable() bool {
+	return o.format == "" || o.format == formatTable
+}
+
+// truncate 在未指定 --no-trunc 时截断过长的字段
+func (o *outputOptions) truncate(value string, max int) string {
+	if o.noTrunc || len(value) <= max {
+		return value
+	}
+	return value[:max-3] + "..."
+}
+
+// writeStructured 以json、yaml或Go模板格式输出列表
+// json和yaml输出整个列表，Go模板对列表中的每一项各输出一行；
+// 模板以 "table " 开头时与docker一样对齐各列，并在第一行输出表头
+func (o *outputOptions) writeStructured(w io.Writer, items interface{}) error {
+	switch o.format {
+	case formatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(items)
+	case formatYAML:
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		defer encoder.Close()
+		return encoder.Encode(items)
+	}
+
+	format, table := strings.CutPrefix(o.format, tablePrefix)
+	tmpl, err := template.New("format").Funcs(templateFuncs).Parse(templateEscapes.Replace(format))
+	if err != nil {
+		return fmt.Errorf("无效的格式模板: %v", err)
+	}
+
+	list := reflect.ValueOf(items)
+	if list.Kind() != reflect.Slice {
+		return fmt.Errorf("无法按模板输出 %T", items)
+	}
+
+	if table {
+		tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+		defer tw.Flush()
+		w = tw
+		// 表头使用同一个模板，字段的值替换为表头，无法生成表头时不输出表头
+		var header strings.Builder
+		if err := tmpl.Execute(&header, templateHeaders(list.Type().Elem(), 0)); err == nil {
+			fmt.Fprintln(w, header.String())
+		}
+	}
+
+	for i := 0; i < list.Len(); i++ {
+		if err := tmpl.Execute(w, list.Index(i).Interface()); err != nil {
+			return fmt.Errorf("执行格式模板失败: %v", err)
+		}
+		fmt.Fprintln(w)
+	}
+	return nil
+}
+
+// templateHeaders 返回结构体各字段对应的表头，字段名按单词拆分后转换为大写，如 LatestTag 对应 LATEST TAG
+// 字符串列表字段的表头也是列表，嵌套的结构体按字段展开，使 join 和 .Config.OS 等写法同样可以生成表头
+func templateHeaders(t reflect.Type, depth int) map[string]interface{} {
+	headers := map[string]interface{}{}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return headers
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		header := strings.ToUpper(splitWords(field.Name))
+		ft := field.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		switch {
+		case ft.Kind() == reflect.Struct && depth < 3:
+			headers[field.Name] = templateHeaders(ft, depth+1)
+		case ft.Kind() == reflect.Slice && ft.Elem().Kind() == reflect.String:
+			headers[field.Name] = []string{header}
+		default:
+			headers[field.Name] = header
+		}
+	}
+	return headers
+}
+
+// splitWords 在驼峰命名的单词之间插入空格，如 ManifestDigest 转换为 Manifest Digest
+func splitWords(name string) string {
+	var sb strings.Builder
+	var prev rune
+	for _, r := range name {
+		if unicode.IsUpper(r) && unicode.IsLower(prev) {
+			sb.WriteByte(' ')
+		}
+		sb.WriteRune(r)
+		prev = r
+	}
+	return sb.String()
+}
+
+// writeOutput 输出非表格格式的结果到标准输出
+func (o *outputOptions) writeOutput(items interface{}) error {
+	return o.writeStructured(os.Stdout, items)
+}
+
+// templateFuncs 模板中可用的函数，与docker的 --format 保持一致
+var templateFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	"join":  strings.Join,
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	"split": strings.Split,
+	"truncate": func(s string, n int) string {
+		if len(s) <= n {
+			return s
+		}
+		return s[:n]
+	},
+}
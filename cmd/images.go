@@ -11,77 +11,132 @@ import (
 )
 
 var (
-	platformFilter string
+	platformFilter    string
+	imagesConcurrency int
+	imagesPerPlatform bool
+	imagesList        listOptions
+	imagesOutput      outputOptions
 )
 
 var imagesCmd = &cobra.Command{
 	Use:   "images",
 	Short: "查看genee的镜像",
-	Long:  `查看私有registry docker.genee.cn中的镜像列表`,
-	RunE:  runImages,
+	Long: `查看私有registry docker.genee.cn中的镜像列表
+
+默认按仓库在registry中的顺序列出，可以使用 --sort 排序、--filter 过滤，
+--limit 限制的是过滤和排序之后的行数。
+
+过滤条件:
+  since=<镜像>        创建时间晚于该镜像（仓库[:标签]，默认为 latest）
+  before=<镜像>       创建时间早于该镜像
+  label=<键>[=<值>]   带有该标签（可以要求标签的值）
+  reference=<模式>    仓库名匹配通配符模式，包含 :标签 时匹配 仓库:标签
+
+示例:
+  docker genee images --sort created --limit 10      # 最近创建的10个镜像
+  docker genee images --sort size --reverse          # 从小到大排列
+  docker genee images -f since=php:8.1 -f 'reference=php*'
+  docker genee images -f label=maintainer=genee`,
+	RunE: runImages,
 }
 
 func init() {
 	rootCmd.AddCommand(imagesCmd)
 	geneeCmd.AddCommand(imagesCmd)
-	
+
 	// 添加平台过滤参数
-	imagesCmd.Flags().StringVar(&platformFilter, "platform", "", "过滤指定平台的镜像 (如: amd64, arm64)")
+	imagesCmd.Flags().StringVar(&platformFilter, "platform", "", "过滤指定平台的镜像，多个平台用逗号分隔 (如: arm64, linux/arm/v7, linux/amd64,linux/arm64)")
+	imagesCmd.Flags().IntVar(&imagesConcurrency, "concurrency", registry.DefaultConcurrency, "并发获取镜像信息的请求数量")
+	imagesCmd.Flags().BoolVar(&imagesPerPlatform, "per-platform", false, "多平台镜像的每个平台各显示一行（含该平台的大小和创建时间）")
+	addListFlags(imagesCmd, &imagesList, 0)
+	addOutputFlags(imagesCmd, &imagesOutput)
 }
 
 func runImages(cmd *cobra.Command, args []string) error {
+	sort, filter, err := imagesList.parse()
+	if err != nil {
+		return err
+	}
+
 	// 创建registry客户端
-	client := registry.NewClient(registryURL)
-	
+	ctx := cmd.Context()
+	client, err := newRegistryClient(ctx)
+	if err != nil {
+		return err
+	}
+	client.SetConcurrency(imagesConcurrency)
+	client.SetReporter(newReporter(imagesOutput.quiet))
+
 	// 检查是否有有效的认证信息
-	if !client.HasValidCredentials() {
+	if !client.HasValidCredentials(ctx) {
 		return fmt.Errorf("请先登录，使用 'docker genee login' 命令")
 	}
-	
+
 	// 获取镜像列表
-	images, err := client.ListImages(platformFilter)
+	images, err := client.ListImages(ctx, registry.ListOptions{
+		Platform:    platformFilter,
+		PerPlatform: imagesPerPlatform,
+		Sort:        sort,
+		Reverse:     imagesList.reverse,
+		Filter:      filter,
+		Limit:       imagesList.limit,
+	})
 	if err != nil {
-		return fmt.Errorf("获取镜像列表失败: %v", err)
+		return registryError("获取镜像列表失败", err)
 	}
-	
+
+	// 只输出镜像名称
+	if imagesOutput.quiet {
+		// 按平台展开时同一镜像有多行（排序后不一定相邻），只输出一次
+		printed := make(map[string]bool)
+		for _, img := range images {
+			name := fmt.Sprintf("%s:%s", img.Repository, img.Tag)
+			if !printed[name] {
+				fmt.Println(name)
+			}
+			printed[name] = true
+		}
+		return nil
+	}
+
+	// json、yaml 或模板格式
+	if !imagesOutput.isTable() {
+		if images == nil {
+			images = []registry.Image{}
+		}
+		return imagesOutput.writeOutput(images)
+	}
+
 	if len(images) == 0 {
 		fmt.Println("没有找到镜像")
 		return nil
 	}
-	
+
 	// 使用tabwriter格式化输出
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
 	fmt.Fprintln(w, "REPOSITORY\tTAG\tPLATFORM\tCREATED\tSIZE")
-	
+
 	for _, img := range images {
-		// 截断过长的仓库名
-		repo := img.Repository
-		if len(repo) > 30 {
-			repo = repo[:27] + "..."
-		}
-		
-		// 截断过长的标签名
-		tag := img.Tag
-		if len(tag) > 20 {
-			tag = tag[:17] + "..."
-		}
-		
+		// 截断过长的仓库名和标签名
+		repo := imagesOutput.truncate(img.Repository, 30)
+		tag := imagesOutput.truncate(img.Tag, 20)
+
 		// 格式化平台信息
 		platforms := strings.Join(img.Platforms, ", ")
 		if platforms == "" {
 			platforms = "unknown"
 		}
-		
+
 		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
 			repo,
 			tag,
 			platforms,
-			img.Created,
+			valueOrDash(img.Created),
 			img.Size)
 	}
-	
+
 	w.Flush()
-	
+
 	fmt.Printf("\n总计: %d 个镜像\n", len(images))
 	return nil
 }
@@ -0,0 +1,207 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/iamfat/docker-genee/internal/registry"
+	"github.com/spf13/cobra"
+)
+
+var (
+	inspectPlatform string
+	inspectRaw      bool
+	inspectOutput   outputOptions
+)
+
+var inspectCmd = &cobra.Command{
+	Use:   "inspect <repository>[:tag|@digest]",
+	Short: "查看镜像的详细信息",
+	Long: `查看镜像的manifest、config和构建历史，无需拉取镜像。
+
+示例:
+  docker genee inspect php:8.1                   # 查看php:8.1的详细信息
+  docker genee inspect php:8.1 --platform arm64  # 查看多架构镜像中arm64平台的信息
+  docker genee inspect php:8.1 --raw             # 输出原始的manifest/index
+  docker genee inspect php:8.1 --format json     # 以缩进的JSON输出manifest/index、config和构建历史
+  docker genee inspect php:8.1 --format '{{.ManifestDigest}}'`,
+	Args: cobra.ExactArgs(1),
+	RunE: runInspect,
+}
+
+func init() {
+	rootCmd.AddCommand(inspectCmd)
+	geneeCmd.AddCommand(inspectCmd)
+
+	inspectCmd.Flags().StringVar(&inspectPlatform, "platform", "", "从多架构镜像中选择平台 (如: linux/amd64, arm64)")
+	inspectCmd.Flags().BoolVar(&inspectRaw, "raw", false, "输出registry返回的原始manifest/index")
+	inspectCmd.Flags().StringVar(&inspectOutput.format, "format", formatTable, "输出格式: table、json、yaml 或Go模板 (如: '{{.Digest}}')")
+}
+
+func runInspect(cmd *cobra.Command, args []string) error {
+	repository, reference := parseReference(args[0])
+	if inspectRaw && !inspectOutput.isTable() {
+		return fmt.Errorf("--raw 和 --format 不能同时使用")
+	}
+
+	// 创建registry客户端
+	ctx := cmd.Context()
+	client, err := newRegistryClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	// 检查是否有有效的认证信息
+	if !client.HasValidCredentials(ctx) {
+		return fmt.Errorf("请先登录，使用 'docker genee login' 命令")
+	}
+
+	inspect, err := client.InspectImage(ctx, repository, reference, inspectPlatform)
+	if err != nil {
+		return registryError("查看镜像失败", err)
+	}
+
+	if inspectRaw {
+		// 指定平台时输出该平台的manifest，否则输出reference直接指向的内容
+		raw := inspect.Raw
+		if inspectPlatform != "" {
+			raw = inspect.ManifestRaw
+		}
+		os.Stdout.Write(raw)
+		if !bytes.HasSuffix(raw, []byte("\n")) {
+			fmt.Println()
+		}
+		return nil
+	}
+
+	// 与 docker inspect 一致，json和yaml输出只包含一项的列表
+	if !inspectOutput.isTable() {
+		return inspectOutput.writeOutput([]*registry.ImageInspect{inspect})
+	}
+
+	printInspect(inspect)
+	return nil
+}
+
+// parseReference 解析 repo:tag 或 repo@digest，未指定标签时使用latest
+func parseReference(ref string) (string, string) {
+	if repository, digest, ok := strings.Cut(ref, "@"); ok {
+		return repository, digest
+	}
+	if i := strings.LastIndex(ref, ":"); i > 0 && !strings.Contains(ref[i:], "/") {
+		return ref[:i], ref[i+1:]
+	}
+	return ref, "latest"
+}
+
+// printInspect 以易读的格式输出镜像详情
+func printInspect(inspect *registry.ImageInspect) {
+	config := inspect.Config.Config
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "名称:\t%s:%s\n", inspect.Repository, inspect.Reference)
+	fmt.Fprintf(w, "摘要:\t%s\n", inspect.Digest)
+	fmt.Fprintf(w, "类型:\t%s\n", inspect.MediaType)
+
+	// 多架构镜像列出所有平台
+	if inspect.Index != nil {
+		fmt.Fprintf(w, "平台列表:\t\n")
+		for _, descriptor := range inspect.Index.Manifests {
+			if descriptor.Platform == nil {
+				continue
+			}
+			fmt.Fprintf(w, "  %s\t%s\n", descriptor.Platform.String(), descriptor.Digest)
+		}
+		fmt.Fprintf(w, "清单摘要:\t%s\n", inspect.ManifestDigest)
+	}
+
+	fmt.Fprintf(w, "平台:\t%s\n", inspect.Platform)
+	fmt.Fprintf(w, "创建时间:\t%s\n", valueOrDash(inspect.Created))
+	if inspect.Config.Author != "" {
+		fmt.Fprintf(w, "作者:\t%s\n", inspect.Config.Author)
+	}
+	fmt.Fprintf(w, "大小:\t%s\n", registry.FormatSize(inspect.Manifest.TotalSize()))
+	fmt.Fprintf(w, "用户:\t%s\n", valueOrDash(config.User))
+	fmt.Fprintf(w, "工作目录:\t%s\n", valueOrDash(config.WorkingDir))
+	fmt.Fprintf(w, "入口点:\t%s\n", formatCommand(config.Entrypoint))
+	fmt.Fprintf(w, "命令:\t%s\n", formatCommand(config.Cmd))
+	fmt.Fprintf(w, "端口:\t%s\n", valueOrDash(strings.Join(sortedKeys(config.ExposedPorts), ", ")))
+	w.Flush()
+
+	fmt.Println("\n环境变量:")
+	if len(config.Env) == 0 {
+		fmt.Println("  -")
+	}
+	for _, env := range config.Env {
+		fmt.Printf("  %s\n", env)
+	}
+
+	fmt.Println("\n标签:")
+	if len(config.Labels) == 0 {
+		fmt.Println("  -")
+	}
+	labelKeys := make([]string, 0, len(config.Labels))
+	for key := range config.Labels {
+		labelKeys = append(labelKeys, key)
+	}
+	sort.Strings(labelKeys)
+	for _, key := range labelKeys {
+		fmt.Printf("  %s=%s\n", key, config.Labels[key])
+	}
+
+	fmt.Println("\n构建历史:")
+	hw := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(hw, "CREATED\tCREATED BY\tSIZE")
+	// 与 docker history 一致，最新的一步显示在最前面
+	for i := len(inspect.History) - 1; i >= 0; i-- {
+		entry := inspect.History[i]
+
+		createdBy := strings.Join(strings.Fields(entry.CreatedBy), " ")
+		if len(createdBy) > 60 {
+			createdBy = createdBy[:57] + "..."
+		}
+
+		size := "0 B"
+		if !entry.EmptyLayer {
+			size = registry.FormatSize(entry.Size)
+		}
+
+		fmt.Fprintf(hw, "%s\t%s\t%s\n", valueOrDash(entry.Created), valueOrDash(createdBy), size)
+	}
+	hw.Flush()
+}
+
+// formatCommand 以JSON数组的形式显示命令，与Dockerfile的exec形式一致
+func formatCommand(command []string) string {
+	if len(command) == 0 {
+		return "-"
+	}
+	data, err := json.Marshal(command)
+	if err != nil {
+		return strings.Join(command, " ")
+	}
+	return string(data)
+}
+
+// sortedKeys 返回排序后的map键
+func sortedKeys(m map[string]struct{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// valueOrDash 空值显示为 -
+func valueOrDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
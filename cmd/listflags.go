@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"github.com/iamfat/docker-genee/internal/registry"
+	"github.com/spf13/cobra"
+)
+
+// listOptions 镜像列表类命令共用的排序、过滤和数量限制参数
+type listOptions struct {
+	sort    string
+	reverse bool
+	filters []string
+	limit   int
+}
+
+// addListFlags 为命令添加 --sort、--reverse、--filter 和 --limit 参数，defaultLimit 为0时默认不限制
+func addListFlags(cmd *cobra.Command, opts *listOptions, defaultLimit int) {
+	cmd.Flags().StringVar(&opts.sort, "sort", "", "排序字段: created（从新到旧）、size（从大到小）、name、tags（标签数量从多到少）")
+	cmd.Flags().BoolVar(&opts.reverse, "reverse", false, "按相反的顺序排列")
+	cmd.Flags().StringArrayVarP(&opts.filters, "filter", "f", nil, "过滤条件，可以多次指定 (如: since=php:8.1, before=php:8.3, label=maintainer=genee, reference='php*')")
+	cmd.Flags().IntVar(&opts.limit, "limit", defaultLimit, "最多显示的行数，0表示不限制")
+}
+
+// parse 解析排序字段和过滤条件
+func (o *listOptions) parse() (registry.SortField, registry.Filter, error) {
+	sort, err := registry.ParseSortField(o.sort)
+	if err != nil {
+		return "", registry.Filter{}, err
+	}
+	filter, err := registry.ParseFilters(o.filters)
+	if err != nil {
+		return "", registry.Filter{}, err
+	}
+	return sort, filter, nil
+}
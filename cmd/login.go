@@ -2,7 +2,9 @@ package cmd
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 	"syscall"
@@ -12,53 +14,137 @@ import (
 	"golang.org/x/term"
 )
 
+var (
+	loginUsername      string
+	loginPassword      string
+	loginPasswordStdin bool
+)
+
 var loginCmd = &cobra.Command{
 	Use:   "login",
 	Short: "登录到基理镜像源",
-	Long:  `登录到基理科技私有镜像源`,
-	RunE:  runLogin,
+	Long: `登录到基理科技私有镜像源
+
+未指定用户名或密码时会在终端上提示输入，也可以通过 DOCKER_GENEE_USERNAME 和
+DOCKER_GENEE_PASSWORD 环境变量提供。
+
+示例:
+  docker genee login
+  docker genee login -u ci-bot
+  echo "$TOKEN" | docker genee login -u ci-bot --password-stdin`,
+	Args: cobra.NoArgs,
+	RunE: runLogin,
 }
 
 func init() {
 	rootCmd.AddCommand(loginCmd)
 	geneeCmd.AddCommand(loginCmd)
+
+	loginCmd.Flags().StringVarP(&loginUsername, "username", "u", "", "用户名")
+	loginCmd.Flags().StringVarP(&loginPassword, "password", "p", "", "密码")
+	loginCmd.Flags().BoolVar(&loginPasswordStdin, "password-stdin", false, "从标准输入读取密码")
 }
 
 func runLogin(cmd *cobra.Command, args []string) error {
+	username := loginUsername
+	password := loginPassword
+
+	// 未通过参数指定用户名时使用环境变量
+	if username == "" {
+		username = os.Getenv("DOCKER_GENEE_USERNAME")
+	}
+
+	// 与 docker login 相同的参数检查
+	if password != "" {
+		fmt.Fprintln(os.Stderr, "警告！通过命令行传递 --password 是不安全的，请使用 --password-stdin。")
+		if loginPasswordStdin {
+			return fmt.Errorf("--password 和 --password-stdin 不能同时使用")
+		}
+	}
+
+	if loginPasswordStdin {
+		if username == "" {
+			return fmt.Errorf("使用 --password-stdin 时必须指定 --username 或 DOCKER_GENEE_USERNAME")
+		}
+		contents, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("读取密码失败: %v", err)
+		}
+		password = strings.TrimSuffix(string(contents), "\n")
+		password = strings.TrimSuffix(password, "\r")
+		// 标准输入已经读完，不能再提示输入密码
+		if password == "" {
+			return fmt.Errorf("密码不能为空")
+		}
+	}
+
+	// 未通过参数指定密码时使用环境变量
+	if password == "" && !loginPasswordStdin {
+		password = os.Getenv("DOCKER_GENEE_PASSWORD")
+	}
+
 	fmt.Printf("登录到 %s\n", registryURL)
-	
-	// 获取用户名
-	reader := bufio.NewReader(os.Stdin)
-	fmt.Print("用户名: ")
-	username, err := reader.ReadString('\n')
-	if err != nil {
-		return fmt.Errorf("读取用户名失败: %v", err)
+
+	// 仍然缺少用户名或密码时在终端上提示输入
+	if username == "" || password == "" {
+		if !term.IsTerminal(int(syscall.Stdin)) {
+			return fmt.Errorf("无法在非终端设备上进行交互式登录，请使用 --username 和 --password-stdin")
+		}
 	}
-	username = strings.TrimSpace(username)
-	
-	// 获取密码（隐藏输入）
-	fmt.Print("密码: ")
-	bytePassword, err := term.ReadPassword(int(syscall.Stdin))
-	if err != nil {
-		return fmt.Errorf("读取密码失败: %v", err)
+
+	if username == "" {
+		// 获取用户名
+		reader := bufio.NewReader(os.Stdin)
+		fmt.Print("用户名: ")
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return fmt.Errorf("读取用户名失败: %v", err)
+		}
+		username = strings.TrimSpace(line)
+		if username == "" {
+			return fmt.Errorf("用户名不能为空")
+		}
+	}
+
+	if password == "" {
+		// 获取密码（隐藏输入）
+		fmt.Print("密码: ")
+		bytePassword, err := term.ReadPassword(int(syscall.Stdin))
+		if err != nil {
+			return fmt.Errorf("读取密码失败: %v", err)
+		}
+		fmt.Println() // 换行
+		password = string(bytePassword)
+	}
+
+	if password == "" {
+		return fmt.Errorf("密码不能为空")
 	}
-	fmt.Println() // 换行
-	
-	password := string(bytePassword)
-	
+
 	// 创建registry客户端
-	client := registry.NewClient(registryURL)
-	
+	ctx := cmd.Context()
+	client, err := newRegistryClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	// 先将旧版本保存的明文凭证导入加密存储，避免覆盖本次登录保存的凭证
+	if store, err := client.MigrateLocalCredentials(ctx); err != nil && !errors.Is(err, registry.ErrNoCredentialStore) {
+		fmt.Fprintf(os.Stderr, "迁移明文凭证失败: %v\n", err)
+	} else if store != "" {
+		fmt.Fprintf(os.Stderr, "已将明文凭证迁移到%s并删除明文文件\n", store)
+	}
+
 	// 尝试登录
-	if err := client.Login(username, password); err != nil {
-		return fmt.Errorf("登录失败: %v", err)
+	if err := client.Login(ctx, username, password); err != nil {
+		return registryError("登录失败", err)
 	}
-	
-	// 保存认证信息
-	if err := client.SaveCredentials(username, password); err != nil {
+
+	// 保存认证信息到本地加密存储，认证信息已经保存在Docker凭证存储中，没有可用的加密存储时跳过
+	if err := client.SaveCredentials(ctx); err != nil && !errors.Is(err, registry.ErrNoCredentialStore) {
 		return fmt.Errorf("保存认证信息失败: %v", err)
 	}
-	
+
 	fmt.Println("登录成功！")
 	return nil
 }
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var logoutCmd = &cobra.Command{
+	Use:   "logout",
+	Short: "从基理镜像源登出",
+	Long:  `从凭证助手、Docker配置文件和本地凭证文件中删除基理科技私有镜像源的认证信息`,
+	Args:  cobra.NoArgs,
+	RunE:  runLogout,
+}
+
+func init() {
+	rootCmd.AddCommand(logoutCmd)
+	geneeCmd.AddCommand(logoutCmd)
+}
+
+func runLogout(cmd *cobra.Command, args []string) error {
+	fmt.Printf("从 %s 登出\n", registryURL)
+
+	ctx := cmd.Context()
+	client, err := newRegistryClient(ctx)
+	if err != nil {
+		return err
+	}
+	results := client.Logout(ctx)
+
+	removed := 0
+	var failed bool
+	for _, result := range results {
+		switch {
+		case result.Err != nil:
+			failed = true
+			fmt.Printf("  %s: 删除失败: %v\n", result.Store, result.Err)
+		case result.Removed:
+			removed++
+			fmt.Printf("  %s: 已删除\n", result.Store)
+		default:
+			fmt.Printf("  %s: 未找到认证信息\n", result.Store)
+		}
+	}
+
+	if failed {
+		return fmt.Errorf("部分认证信息删除失败")
+	}
+	if removed == 0 {
+		fmt.Println("没有找到已保存的认证信息")
+		return nil
+	}
+
+	fmt.Println("登出成功！")
+	return nil
+}
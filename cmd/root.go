@@ -1,16 +1,51 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
 
+	"github.com/iamfat/docker-genee/internal/config"
+	"github.com/iamfat/docker-genee/internal/registry"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 )
 
 var (
 	registryURL = "docker.genee.cn"
 	configDir   string
+	noCache     bool
 	Version     = "1.0.4"
+
+	// profileName 使用的镜像源配置名称，cacheMaxSize 缓存容量上限
+	profileName  string
+	cacheMaxSize = registry.DefaultCacheMaxSize
+
+	// registryScheme 镜像源配置中指定的协议，insecure 允许HTTP并跳过TLS校验
+	registryScheme string
+	insecure       bool
+
+	// caCertFile、clientCertFile、clientKeyFile 额外的CA证书和客户端证书
+	caCertFile     string
+	clientCertFile string
+	clientKeyFile  string
+
+	// Docker CLI调用插件时会原样传入全局参数
+	dockerConfigDir string
+	dockerContext   string
+	dockerHost      []string
+	dockerLogLevel  string
+	dockerDebug     bool
+
+	// timeout 整个命令的超时时间，timeoutCtx 和 cancelTimeout 在命令执行前创建
+	timeout       time.Duration
+	timeoutCtx    context.Context
+	cancelTimeout context.CancelFunc
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -22,7 +57,27 @@ var rootCmd = &cobra.Command{
 - 查看镜像列表
 - 搜索镜像（支持通配符和平台限制）`,
 	SilenceErrors: true,
-	SilenceUsage: true,
+	SilenceUsage:  true,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// 获取插件元数据时不读取配置，避免配置文件错误导致Docker CLI无法识别插件；
+		// config 命令自己读写配置文件，配置有误时需要用它修复
+		if cmd == metadataCmd || isConfigCommand(cmd) {
+			return nil
+		}
+		// 超时作用于整个命令，子命令通过 cmd.Context() 获取
+		if timeout > 0 {
+			timeoutCtx, cancelTimeout = context.WithTimeout(cmd.Context(), timeout)
+			cmd.SetContext(timeoutCtx)
+		}
+		if err := applyProfile(cmd); err != nil {
+			// 只有列出和查看镜像的命令依赖配置中的默认值，其余命令忽略无效的配置
+			if usesProfileDefaults(cmd) {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "警告: %v，忽略该配置\n", err)
+		}
+		return nil
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// 如果没有子命令，显示帮助信息
 		return cmd.Help()
@@ -31,38 +86,220 @@ var rootCmd = &cobra.Command{
 
 // geneeCmd 是主要的命令，包含所有子命令，用于支持 docker genee 的调用方式
 var geneeCmd = &cobra.Command{
-	Use:   "genee",
-	Short: "基理科技镜像源操作",
-	Long:  `基理科技镜像源的各种操作命令`,
+	Use:    "genee",
+	Short:  "基理科技镜像源操作",
+	Long:   `基理科技镜像源的各种操作命令`,
 	Hidden: true,
 }
 
+// newRegistryClient 创建按全局参数配置好的registry客户端
+func newRegistryClient(ctx context.Context) (*registry.Client, error) {
+	client, err := registry.NewClient(registryURL)
+	if err != nil {
+		return nil, err
+	}
+	if registryScheme != "" {
+		if err := client.SetScheme(registryScheme); err != nil {
+			return nil, err
+		}
+	}
+	if insecure {
+		client.SetInsecure(true)
+	}
+	if err := client.SetTLSFiles(caCertFile, clientCertFile, clientKeyFile); err != nil {
+		return nil, err
+	}
+	client.SetDockerConfigDir(resolvedDockerConfigDir())
+	client.SetConfigDir(configDir)
+	if !noCache {
+		client.SetCache(newCache())
+	}
+	return client, nil
+}
+
+// applyProfile 应用选择的镜像源配置：--profile 参数、DOCKER_GENEE_PROFILE 环境变量或当前配置
+// 命令行中显式指定的参数优先于配置中的值
+func applyProfile(cmd *cobra.Command) error {
+	cfg, err := config.Load(configPath())
+	if err != nil {
+		return err
+	}
+
+	name := profileName
+	if name == "" {
+		name = os.Getenv("DOCKER_GENEE_PROFILE")
+	}
+	explicit := name != ""
+	if name == "" {
+		name = cfg.Current
+	}
+	if name == "" {
+		return nil
+	}
+
+	profile, ok := cfg.Profile(name)
+	if !ok {
+		if explicit {
+			return fmt.Errorf("配置 %s 不存在", name)
+		}
+		return nil
+	}
+	if err := profile.Validate(); err != nil {
+		return fmt.Errorf("配置 %s 无效: %v", name, err)
+	}
+	maxSize := cacheMaxSize
+	if profile.Cache.MaxSize != "" {
+		if maxSize, err = config.ParseSize(profile.Cache.MaxSize); err != nil {
+			return fmt.Errorf("配置 %s 中的 max-size 无效: %v", name, err)
+		}
+	}
+
+	flags := cmd.Flags()
+	if !flags.Changed("registry") {
+		registryURL = profile.URL
+		registryScheme = profile.Scheme
+		if !flags.Changed("cacert") && profile.CACert != "" {
+			caCertFile = profile.CACert
+		}
+	}
+	if !flags.Changed("no-cache") && !profile.CacheEnabled() {
+		noCache = true
+	}
+	cacheMaxSize = maxSize
+
+	// 列出和查看镜像的命令的 --platform 和 --concurrency 参数使用配置中的默认值，
+	// config add 等命令的同名参数是要保存的值，不能被当前配置覆盖
+	if !usesProfileDefaults(cmd) {
+		return nil
+	}
+	defaults := map[string]string{"platform": profile.Platform}
+	if profile.Concurrency > 0 {
+		defaults["concurrency"] = strconv.Itoa(profile.Concurrency)
+	}
+	for flagName, value := range defaults {
+		flag := flags.Lookup(flagName)
+		if value == "" || flag == nil || flag.Changed {
+			continue
+		}
+		if err := flag.Value.Set(value); err != nil {
+			return fmt.Errorf("配置 %s 中的 %s 无效: %v", name, flagName, err)
+		}
+	}
+	return nil
+}
+
+// usesProfileDefaults 判断命令的参数是否使用镜像源配置中的默认值
+func usesProfileDefaults(cmd *cobra.Command) bool {
+	switch cmd {
+	case imagesCmd, searchCmd, tagsCmd, inspectCmd:
+		return true
+	}
+	return false
+}
+
+// isConfigCommand 判断是否为 config 命令或其子命令
+func isConfigCommand(cmd *cobra.Command) bool {
+	for c := cmd; c != nil; c = c.Parent() {
+		if c == configCmd {
+			return true
+		}
+	}
+	return false
+}
+
+// resolvedDockerConfigDir 返回Docker CLI的配置目录：--config 参数、DOCKER_CONFIG 环境变量或 ~/.docker
+func resolvedDockerConfigDir() string {
+	if dockerConfigDir != "" {
+		return dockerConfigDir
+	}
+	return registry.DefaultDockerConfigDir()
+}
+
+// newReporter 创建进度和诊断信息的输出，只在标准错误是终端且未指定 --quiet 时显示
+func newReporter(quiet bool) registry.Reporter {
+	if quiet || !term.IsTerminal(int(os.Stderr.Fd())) {
+		return registry.NopReporter{}
+	}
+	return registry.NewTerminalReporter(os.Stderr)
+}
+
+// addDockerGlobalFlags 添加Docker CLI的全局标志
+// 只使用 --config，其余标志只用于兼容，避免插件解析参数失败；
+// 上下文选择的是Docker守护进程，插件直接访问registry，不支持按上下文选择，--context 在帮助中注明
+func addDockerGlobalFlags(cmd *cobra.Command) {
+	flags := cmd.PersistentFlags()
+	flags.StringVar(&dockerConfigDir, "config", "", "Docker客户端配置文件所在目录 (默认: $DOCKER_CONFIG 或 ~/.docker)")
+	flags.StringVarP(&dockerContext, "context", "c", "", "不支持按Docker上下文选择镜像源，上下文只决定Docker守护进程，插件忽略该参数")
+	flags.StringSliceVarP(&dockerHost, "host", "H", nil, "Docker守护进程地址（插件不使用）")
+	flags.StringVarP(&dockerLogLevel, "log-level", "l", "info", "日志级别（插件不使用）")
+	flags.BoolVarP(&dockerDebug, "debug", "D", false, "启用调试模式（插件不使用）")
+	for _, name := range []string{"host", "log-level", "debug"} {
+		flags.MarkHidden(name)
+	}
+}
+
 // Execute adds all child commands to the root command and sets flags appropriately.
+// 收到 SIGINT 或 SIGTERM 时取消命令的上下文，正在进行的请求会尽快停止
 func Execute() error {
-	return rootCmd.Execute()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	err := rootCmd.ExecuteContext(ctx)
+	if cancelTimeout != nil {
+		cancelTimeout()
+	}
+	return interruptedError(ctx, err)
 }
 
-func init() {
-	// 设置配置目录
-	home, err := os.UserHomeDir()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "获取用户主目录失败: %v\n", err)
-		os.Exit(1)
+// interruptedError 将信号取消和超时导致的错误转换为易懂的提示
+// 取消的原因以上下文的状态为准，因为部分错误在包装时丢失了原始错误
+func interruptedError(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	if ctx.Err() != nil {
+		return fmt.Errorf("操作已取消")
 	}
+	if timeoutCtx != nil && errors.Is(timeoutCtx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("操作超时（--timeout %s）", timeout)
+	}
+	return err
+}
 
-	configDir = home + "/.docker-genee"
+func init() {
+	// 设置配置目录，可以通过 DOCKER_GENEE_CONFIG 环境变量指定
+	configDir = registry.DefaultConfigDir()
 
 	// 将 genee 命令添加到根命令，支持 docker genee 的调用方式
 	rootCmd.AddCommand(geneeCmd)
 
 	// 全局标志 - 同时添加到 rootCmd 和 geneeCmd
-	rootCmd.PersistentFlags().StringVar(&registryURL, "registry", registryURL, "镜像源地址 (默认: docker.genee.cn)")
-	geneeCmd.PersistentFlags().StringVar(&registryURL, "registry", registryURL, "镜像源地址 (默认: docker.genee.cn)")
-	
+	rootCmd.PersistentFlags().StringVar(&registryURL, "registry", registryURL, "镜像源地址，可以带协议如 http://localhost:5000 (默认: docker.genee.cn)")
+	geneeCmd.PersistentFlags().StringVar(&registryURL, "registry", registryURL, "镜像源地址，可以带协议如 http://localhost:5000 (默认: docker.genee.cn)")
+	rootCmd.PersistentFlags().BoolVar(&insecure, "insecure", false, "允许使用HTTP访问镜像源并跳过TLS证书校验")
+	geneeCmd.PersistentFlags().BoolVar(&insecure, "insecure", false, "允许使用HTTP访问镜像源并跳过TLS证书校验")
+	rootCmd.PersistentFlags().StringVar(&caCertFile, "cacert", "", "校验镜像源证书使用的CA证书文件")
+	geneeCmd.PersistentFlags().StringVar(&caCertFile, "cacert", "", "校验镜像源证书使用的CA证书文件")
+	rootCmd.PersistentFlags().StringVar(&clientCertFile, "cert", "", "客户端证书文件 (mTLS)")
+	geneeCmd.PersistentFlags().StringVar(&clientCertFile, "cert", "", "客户端证书文件 (mTLS)")
+	rootCmd.PersistentFlags().StringVar(&clientKeyFile, "key", "", "客户端私钥文件 (mTLS)")
+	geneeCmd.PersistentFlags().StringVar(&clientKeyFile, "key", "", "客户端私钥文件 (mTLS)")
+	rootCmd.PersistentFlags().BoolVar(&noCache, "no-cache", false, "不使用本地元数据缓存")
+	geneeCmd.PersistentFlags().BoolVar(&noCache, "no-cache", false, "不使用本地元数据缓存")
+
+	rootCmd.PersistentFlags().StringVar(&profileName, "profile", "", "使用的镜像源配置名称 (默认: 当前配置)")
+	geneeCmd.PersistentFlags().StringVar(&profileName, "profile", "", "使用的镜像源配置名称 (默认: 当前配置)")
+	rootCmd.PersistentFlags().DurationVar(&timeout, "timeout", 0, "命令的超时时间，如 30s、5m (默认: 不限制)")
+	geneeCmd.PersistentFlags().DurationVar(&timeout, "timeout", 0, "命令的超时时间，如 30s、5m (默认: 不限制)")
+
+	// Docker CLI的全局标志，作为插件运行时由Docker CLI传入
+	addDockerGlobalFlags(rootCmd)
+	addDockerGlobalFlags(geneeCmd)
+
 	// 添加版本和帮助标志，确保在genee前缀后也能正常工作
 	rootCmd.Version = Version
 	rootCmd.SetVersionTemplate("docker-genee version {{.Version}}\n")
-	
+
 	geneeCmd.Version = Version
 	geneeCmd.SetVersionTemplate("docker-genee version {{.Version}}\n")
 }
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"strings"
@@ -11,8 +12,13 @@ import (
 )
 
 var (
-	platform string
-	limit    int
+	platform          string
+	searchIgnoreCase  bool
+	searchRegex       bool
+	searchSemver      string
+	searchConcurrency int
+	searchList        listOptions
+	searchOutput      outputOptions
 )
 
 var searchCmd = &cobra.Command{
@@ -20,10 +26,32 @@ var searchCmd = &cobra.Command{
 	Short: "搜索基理镜像",
 	Long: `搜索基理科技镜像源中的镜像，支持通配符和平台限制。
 
+查询的格式为 仓库[:标签]，两部分都完整匹配，支持的通配符：
+  *        不包含 / 的任意字符串
+  **       任意字符串（可以跨越 /，用于多级命名空间）
+  ?        单个字符
+  [abc]    字符集合，支持 [a-z] 和 [!abc]
+  {a,b}    任意一个备选
+
+使用 --regex 时两部分按正则表达式匹配（部分匹配，需要完整匹配时使用 ^ 和 $）。
+标签部分以 ^、~、>、<、=、! 开头或使用 --semver 时，按版本约束匹配标签，
+标签中的后缀（如 -alpine）不影响比较，匹配的标签按版本号从新到旧排列。
+
+结果可以使用 --sort 排序、--filter 过滤（条件与 images 命令相同，按每个仓库的主要标签判断），
+--limit 限制的是过滤和排序之后的行数（每个匹配的标签一行）。
+
 示例:
-  docker genee search ph*          # 搜索以ph开头的镜像
-  docker genee search php:a*       # 搜索php镜像中以a开头的标签
-  docker genee search ph* --platform arm64  # 限制平台为arm64`,
+  docker genee search 'ph*'                  # 搜索以ph开头的镜像
+  docker genee search 'php:a*'               # 搜索php镜像中以a开头的标签
+  docker genee search 'php:8.{1,2}-*'        # 搜索php镜像中8.1和8.2的标签
+  docker genee search 'library/**'           # 搜索library下所有层级的镜像
+  docker genee search 'PH*' -i               # 忽略大小写
+  docker genee search 'php:^8.1'             # php镜像中兼容8.1的版本（>=8.1.0,<9.0.0）
+  docker genee search php --semver '>=8.1,<8.3'  # php镜像中8.1和8.2的版本
+  docker genee search --regex '^ph.*:^8\.[12]-'  # 使用正则表达式
+  docker genee search 'ph*' --platform arm64 # 限制平台为arm64
+  docker genee search 'ph*' --sort created   # 按创建时间从新到旧排列
+  docker genee search '*' -f label=maintainer=genee --limit 0`,
 	Args: cobra.ExactArgs(1),
 	RunE: runSearch,
 }
@@ -31,29 +59,75 @@ var searchCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(searchCmd)
 	geneeCmd.AddCommand(searchCmd)
-	
+
 	// 搜索相关标志
-	searchCmd.Flags().StringVar(&platform, "platform", "", "限制搜索的平台 (如: linux/amd64, linux/arm64, amd64, arm64)")
-	searchCmd.Flags().IntVar(&limit, "limit", 100, "搜索结果数量限制")
+	searchCmd.Flags().StringVar(&platform, "platform", "", "限制搜索的平台，多个平台用逗号分隔 (如: linux/amd64, linux/arm/v7, amd64,arm64)")
+	searchCmd.Flags().BoolVarP(&searchIgnoreCase, "ignore-case", "i", false, "仓库名和标签匹配时忽略大小写")
+	searchCmd.Flags().BoolVar(&searchRegex, "regex", false, "仓库名和标签按正则表达式匹配")
+	searchCmd.Flags().StringVar(&searchSemver, "semver", "", "按版本约束匹配标签 (如: '>=8.1,<8.3', '^8.1', '~7.4 || 8.x')")
+	searchCmd.Flags().IntVar(&searchConcurrency, "concurrency", registry.DefaultConcurrency, "并发获取镜像信息的请求数量")
+	addListFlags(searchCmd, &searchList, 100)
+	addOutputFlags(searchCmd, &searchOutput)
 }
 
 func runSearch(cmd *cobra.Command, args []string) error {
 	query := args[0]
-	
+	sort, filter, err := searchList.parse()
+	if err != nil {
+		return err
+	}
+
 	// 创建registry客户端
-	client := registry.NewClient(registryURL)
-	
+	ctx := cmd.Context()
+	client, err := newRegistryClient(ctx)
+	if err != nil {
+		return err
+	}
+	client.SetConcurrency(searchConcurrency)
+	client.SetReporter(newReporter(searchOutput.quiet))
+
 	// 检查是否有有效的认证信息
-	if !client.HasValidCredentials() {
+	if !client.HasValidCredentials(ctx) {
 		return fmt.Errorf("请先登录，使用 'docker genee login' 命令")
 	}
-	
+
 	// 搜索镜像
-	results, err := client.SearchImages(query, platform, limit)
+	results, err := client.SearchImages(ctx, query, registry.SearchOptions{
+		Platform:   platform,
+		Limit:      searchList.limit,
+		IgnoreCase: searchIgnoreCase,
+		Regex:      searchRegex,
+		Semver:     searchSemver,
+		Sort:       sort,
+		Reverse:    searchList.reverse,
+		Filter:     filter,
+	})
 	if err != nil {
-		return fmt.Errorf("搜索镜像失败: %v", err)
+		return registryError("搜索镜像失败", err)
+	}
+
+	// 只输出镜像名称，每个匹配的标签一行
+	if searchOutput.quiet {
+		for _, result := range results {
+			tags := result.MatchedTags
+			if len(tags) == 0 {
+				tags = []string{result.LatestTag}
+			}
+			for _, tag := range tags {
+				fmt.Printf("%s:%s\n", result.Name, tag)
+			}
+		}
+		return nil
+	}
+
+	// json、yaml 或模板格式
+	if !searchOutput.isTable() {
+		if results == nil {
+			results = []registry.SearchResult{}
+		}
+		return searchOutput.writeOutput(results)
 	}
-	
+
 	if len(results) == 0 {
 		fmt.Printf("没有找到匹配 '%s' 的镜像", query)
 		if platform != "" {
@@ -62,72 +136,70 @@ func runSearch(cmd *cobra.Command, args []string) error {
 		fmt.Println()
 		return nil
 	}
-	
+
 	// 使用tabwriter格式化输出
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
 	fmt.Fprintln(w, "REPOSITORY\tTAG\tPLATFORM\tCREATED\tSIZE")
-	
+
 	for _, result := range results {
 		// 获取真实的平台信息（而不是硬编码的平台列表）
 		platforms := result.Platforms
 		if len(platforms) == 0 {
 			platforms = []string{"unknown"}
 		}
-		
+
 		// 截断过长的仓库名
-		repo := result.Name
-		if len(repo) > 30 {
-			repo = repo[:27] + "..."
-		}
-		
+		repo := searchOutput.truncate(result.Name, 30)
+
 		// 如果有匹配的标签，为每个标签创建单独的行
 		if len(result.MatchedTags) > 0 {
-			for _, tag := range result.MatchedTags {
+			// 并发获取所有匹配标签的真实平台信息
+			matchedPlatforms := client.GetTagsPlatforms(ctx, result.Name, result.MatchedTags)
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			for i, tag := range result.MatchedTags {
 				// 截断过长的标签名
-				tagDisplay := tag
-				if len(tagDisplay) > 20 {
-					tagDisplay = tagDisplay[:17] + "..."
+				tagDisplay := searchOutput.truncate(tag, 20)
+
+				tagPlatforms := matchedPlatforms[i]
+				if len(tagPlatforms) == 0 {
+					tagPlatforms = []string{"unknown"}
 				}
-				
-				// 为每个标签获取真实的平台信息
-				tagPlatforms := getTagPlatforms(client, result.Name, tag)
 				platformDisplay := strings.Join(tagPlatforms, ", ")
 				if platformDisplay == "" {
 					platformDisplay = "unknown"
 				}
-				
+
 				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
 					repo,
 					tagDisplay,
 					platformDisplay,
-					result.Created,
+					valueOrDash(result.Created),
 					result.Size)
 			}
 		} else {
 			// 没有匹配的标签，显示最新标签
-			tagDisplay := result.LatestTag
-			if len(tagDisplay) > 20 {
-				tagDisplay = tagDisplay[:17] + "..."
-			}
-			
+			tagDisplay := searchOutput.truncate(result.LatestTag, 20)
+
 			// 为最新标签获取真实的平台信息
-			tagPlatforms := getTagPlatforms(client, result.Name, result.LatestTag)
+			tagPlatforms := getTagPlatforms(ctx, client, result.Name, result.LatestTag)
 			platformDisplay := strings.Join(tagPlatforms, ", ")
 			if platformDisplay == "" {
 				platformDisplay = "unknown"
 			}
-			
+
 			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
 				repo,
 				tagDisplay,
 				platformDisplay,
-				result.Created,
+				valueOrDash(result.Created),
 				result.Size)
 		}
 	}
-	
+
 	w.Flush()
-	
+
 	// 计算总行数（包括每个匹配的标签）
 	totalLines := 0
 	for _, result := range results {
@@ -137,20 +209,20 @@ func runSearch(cmd *cobra.Command, args []string) error {
 			totalLines++
 		}
 	}
-	
+
 	fmt.Printf("\n找到 %d 个匹配的镜像，共 %d 行", len(results), totalLines)
 	if platform != "" {
 		fmt.Printf(" (平台: %s)", platform)
 	}
 	fmt.Println()
-	
+
 	return nil
 }
 
 // getTagPlatforms 获取指定标签的真实平台信息
-func getTagPlatforms(client *registry.Client, repository, tag string) []string {
+func getTagPlatforms(ctx context.Context, client *registry.Client, repository, tag string) []string {
 	// 使用与 images.go 相同的方法获取平台信息
-	platforms := client.GetImagePlatforms(repository, tag)
+	platforms := client.GetImagePlatforms(ctx, repository, tag)
 	if len(platforms) == 0 {
 		return []string{"unknown"}
 	}
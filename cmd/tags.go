@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/iamfat/docker-genee/internal/registry"
+	"github.com/spf13/cobra"
+)
+
+var (
+	tagsPlatform    string
+	tagsConcurrency int
+	tagsOutput      outputOptions
+)
+
+var tagsCmd = &cobra.Command{
+	Use:   "tags <repository>[:tag_pattern]",
+	Short: "查看仓库的所有标签",
+	Long: `查看仓库中的所有标签，每个标签的每个平台各显示一行。
+
+示例:
+  docker genee tags php                    # 查看php的所有标签
+  docker genee tags php:8*                 # 查看php中以8开头的标签
+  docker genee tags php --platform arm64   # 只显示arm64平台`,
+	Args: cobra.ExactArgs(1),
+	RunE: runTags,
+}
+
+func init() {
+	rootCmd.AddCommand(tagsCmd)
+	geneeCmd.AddCommand(tagsCmd)
+
+	tagsCmd.Flags().StringVar(&tagsPlatform, "platform", "", "只显示指定平台，多个平台用逗号分隔 (如: linux/amd64, arm64,arm/v7)")
+	tagsCmd.Flags().IntVar(&tagsConcurrency, "concurrency", registry.DefaultConcurrency, "并发获取镜像信息的请求数量")
+	addOutputFlags(tagsCmd, &tagsOutput)
+}
+
+func runTags(cmd *cobra.Command, args []string) error {
+	repository, tagPattern, _ := strings.Cut(args[0], ":")
+
+	// 创建registry客户端
+	ctx := cmd.Context()
+	client, err := newRegistryClient(ctx)
+	if err != nil {
+		return err
+	}
+	client.SetConcurrency(tagsConcurrency)
+	client.SetReporter(newReporter(tagsOutput.quiet))
+
+	// 检查是否有有效的认证信息
+	if !client.HasValidCredentials(ctx) {
+		return fmt.Errorf("请先登录，使用 'docker genee login' 命令")
+	}
+
+	tags, err := client.ListTags(ctx, repository, tagPattern, tagsPlatform)
+	if err != nil {
+		return registryError("获取标签列表失败", err)
+	}
+
+	// 只输出镜像名称，多平台标签只输出一次
+	if tagsOutput.quiet {
+		seen := make(map[string]bool)
+		for _, tag := range tags {
+			if !seen[tag.Tag] {
+				seen[tag.Tag] = true
+				fmt.Printf("%s:%s\n", tag.Repository, tag.Tag)
+			}
+		}
+		return nil
+	}
+
+	// json、yaml 或模板格式
+	if !tagsOutput.isTable() {
+		if tags == nil {
+			tags = []registry.TagInfo{}
+		}
+		return tagsOutput.writeOutput(tags)
+	}
+
+	if len(tags) == 0 {
+		fmt.Printf("没有找到 %s 的标签\n", args[0])
+		return nil
+	}
+
+	// 使用tabwriter格式化输出
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "TAG\tPLATFORM\tDIGEST\tCREATED\tSIZE")
+
+	for _, tag := range tags {
+		// 截断过长的标签名和digest
+		tagDisplay := tagsOutput.truncate(tag.Tag, 20)
+		digest := tag.Digest
+		if !tagsOutput.noTrunc {
+			digest = shortDigest(digest)
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+			tagDisplay,
+			tag.Platform,
+			digest,
+			tag.Created,
+			tag.Size)
+	}
+
+	w.Flush()
+
+	fmt.Printf("\n总计: %d 行\n", len(tags))
+	return nil
+}
+
+// shortDigest 截取digest的前12位用于显示
+func shortDigest(digest string) string {
+	_, hex, ok := strings.Cut(digest, ":")
+	if !ok || len(hex) < 12 {
+		return digest
+	}
+	return hex[:12]
+}
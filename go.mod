@@ -4,7 +4,9 @@ go 1.23.0
 
 require (
 	github.com/spf13/cobra v1.8.0
+	golang.org/x/crypto v0.41.0
 	golang.org/x/term v0.34.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
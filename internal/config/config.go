@@ -0,0 +1,220 @@
+// Package config 读写插件的配置文件 ~/.docker-genee/config.yaml
+// 配置文件中保存多个命名的镜像源配置（profile），并记录当前使用的配置
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/iamfat/docker-genee/internal/registry"
+	"gopkg.in/yaml.v3"
+)
+
+// FileName 配置文件的文件名
+const FileName = "config.yaml"
+
+// Config 表示配置文件
+type Config struct {
+	path string
+
+	// Current 当前使用的配置名称
+	Current string `yaml:"current,omitempty"`
+	// Profiles 按名称保存的镜像源配置
+	Profiles map[string]*Profile `yaml:"profiles,omitempty"`
+}
+
+// Profile 表示一个镜像源的配置
+type Profile struct {
+	// URL 镜像源地址，如 docker.genee.cn 或 localhost:5000
+	URL string `yaml:"url"`
+	// Scheme 访问镜像源使用的协议：https 或 http
+	Scheme string `yaml:"scheme,omitempty"`
+	// CACert 校验镜像源证书使用的CA证书文件
+	CACert string `yaml:"ca,omitempty"`
+	// Platform 默认的平台过滤条件
+	Platform string `yaml:"platform,omitempty"`
+	// Concurrency 默认的并发请求数量
+	Concurrency int `yaml:"concurrency,omitempty"`
+	// Cache 元数据缓存设置
+	Cache CacheConfig `yaml:"cache,omitempty"`
+}
+
+// CacheConfig 表示元数据缓存的设置
+type CacheConfig struct {
+	// Enabled 是否使用缓存，未设置时使用缓存
+	Enabled *bool `yaml:"enabled,omitempty"`
+	// MaxSize 缓存容量上限，如 512MB
+	MaxSize string `yaml:"max-size,omitempty"`
+}
+
+// Load 读取配置文件，文件不存在时返回空配置
+func Load(path string) (*Config, error) {
+	config := &Config{
+		path:     path,
+		Profiles: map[string]*Profile{},
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return config, nil
+		}
+		return nil, err
+	}
+
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("解析配置文件 %s 失败: %v", path, err)
+	}
+	if config.Profiles == nil {
+		config.Profiles = map[string]*Profile{}
+	}
+	return config, nil
+}
+
+// Save 将配置写回文件
+func (c *Config) Save() error {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(c); err != nil {
+		return err
+	}
+	if err := encoder.Close(); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(c.path, buf.Bytes(), 0600)
+}
+
+// Path 返回配置文件的路径
+func (c *Config) Path() string {
+	return c.path
+}
+
+// Names 返回按名称排序的配置名称
+func (c *Config) Names() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Profile 返回指定名称的配置
+func (c *Config) Profile(name string) (*Profile, bool) {
+	profile, ok := c.Profiles[name]
+	return profile, ok
+}
+
+// Add 添加配置，没有当前配置时将其设为当前配置
+func (c *Config) Add(name string, profile *Profile) error {
+	if name == "" {
+		return fmt.Errorf("配置名称不能为空")
+	}
+	if _, ok := c.Profiles[name]; ok {
+		return fmt.Errorf("配置 %s 已存在", name)
+	}
+	if err := profile.Validate(); err != nil {
+		return err
+	}
+
+	c.Profiles[name] = profile
+	if c.Current == "" {
+		c.Current = name
+	}
+	return nil
+}
+
+// Use 设置当前使用的配置
+func (c *Config) Use(name string) error {
+	if _, ok := c.Profiles[name]; !ok {
+		return fmt.Errorf("配置 %s 不存在", name)
+	}
+	c.Current = name
+	return nil
+}
+
+// Remove 删除配置，删除当前配置时清除当前配置
+func (c *Config) Remove(name string) error {
+	if _, ok := c.Profiles[name]; !ok {
+		return fmt.Errorf("配置 %s 不存在", name)
+	}
+	delete(c.Profiles, name)
+	if c.Current == name {
+		c.Current = ""
+	}
+	return nil
+}
+
+// Validate 检查配置是否有效
+func (p *Profile) Validate() error {
+	if p.URL == "" {
+		return fmt.Errorf("镜像源地址不能为空")
+	}
+	switch p.Scheme {
+	case "", "https", "http":
+	default:
+		return fmt.Errorf("不支持的协议: %s", p.Scheme)
+	}
+	if p.Concurrency < 0 {
+		return fmt.Errorf("并发数量不能为负数")
+	}
+	if p.Platform != "" {
+		if _, err := registry.ParsePlatforms(p.Platform); err != nil {
+			return err
+		}
+	}
+	if p.Cache.MaxSize != "" {
+		if _, err := ParseSize(p.Cache.MaxSize); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CacheEnabled 返回是否使用缓存
+func (p *Profile) CacheEnabled() bool {
+	return p.Cache.Enabled == nil || *p.Cache.Enabled
+}
+
+// sizeUnits 容量单位，按1024进位
+var sizeUnits = map[string]int64{
+	"":   1,
+	"B":  1,
+	"K":  1 << 10,
+	"KB": 1 << 10,
+	"M":  1 << 20,
+	"MB": 1 << 20,
+	"G":  1 << 30,
+	"GB": 1 << 30,
+}
+
+// ParseSize 解析 512MB、1G 等形式的容量
+func ParseSize(value string) (int64, error) {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	i := strings.IndexFunc(value, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	number, unit := value, ""
+	if i >= 0 {
+		number, unit = value[:i], strings.TrimSpace(value[i:])
+	}
+
+	multiplier, ok := sizeUnits[unit]
+	if !ok || number == "" {
+		return 0, fmt.Errorf("无效的容量: %s", value)
+	}
+	n, err := strconv.ParseFloat(number, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("无效的容量: %s", value)
+	}
+	return int64(n * float64(multiplier)), nil
+}
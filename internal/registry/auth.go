@@ -0,0 +1,420 @@
+package registry
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// tokenExpiryMargin 提前刷新token的时间余量，避免请求途中过期
+	tokenExpiryMargin = 10 * time.Second
+	// defaultTokenLifetime 令牌服务未返回expires_in时的默认有效期（见distribution token规范）
+	defaultTokenLifetime = 60 * time.Second
+	// oauthClientID 请求refresh token和使用OAuth2接口获取token时的client_id
+	oauthClientID = "docker-genee"
+)
+
+// authChallenge 表示 WWW-Authenticate 头中的一个认证质询
+type authChallenge struct {
+	Scheme     string
+	Parameters map[string]string
+}
+
+// doRequest 发送请求并处理认证
+// 优先使用缓存的Bearer token，否则使用Basic认证；
+// 如果registry返回Bearer质询，则获取对应scope的token并透明地重试请求
+func (c *Client) doRequest(req *http.Request) (*http.Response, error) {
+	scope := scopeForPath(req.URL.Path)
+
+	if token := c.cachedToken(scope); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	} else {
+		c.setBasicAuth(req)
+	}
+
+	resp, err := c.send(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusUnauthorized {
+		return resp, nil
+	}
+
+	// 检查是否为Bearer质询
+	challenge, ok := parseBearerChallenge(resp.Header.Values("WWW-Authenticate"))
+	if !ok {
+		return resp, nil
+	}
+	resp.Body.Close()
+
+	// 质询中的scope优先，其次使用根据路径推断的scope
+	tokenScope := challenge.Parameters["scope"]
+	if tokenScope == "" {
+		tokenScope = scope
+	}
+
+	token, err := c.fetchToken(req.Context(), challenge, tokenScope)
+	if err != nil {
+		return nil, fmt.Errorf("获取访问令牌失败: %w", err)
+	}
+	c.storeToken(scope, token)
+
+	// 使用新token重试请求
+	retry := req.Clone(req.Context())
+	retry.Header.Set("Authorization", "Bearer "+token.Token)
+	return c.send(retry)
+}
+
+// send 在并发限制内发送HTTP请求
+// 遇到网络暂时性错误、429和5xx响应时按指数退避重试，等待期间不占用并发名额
+func (c *Client) send(req *http.Request) (*http.Response, error) {
+	if c.transportErr != nil {
+		return nil, c.transportErr
+	}
+
+	for attempt := 0; ; attempt++ {
+		attemptReq := req
+		if attempt > 0 {
+			var err error
+			if attemptReq, err = rewindRequest(req); err != nil {
+				return nil, err
+			}
+		}
+
+		release, err := c.acquireRequestSlot(req.Context())
+		if err != nil {
+			return nil, err
+		}
+		resp, err := c.httpClient.Do(attemptReq)
+		release()
+
+		if attempt >= maxRetries || !shouldRetry(req, resp, err) {
+			return resp, err
+		}
+
+		delay := retryDelay(attempt, resp)
+		if resp != nil {
+			c.reporter.Infof("%s %s 返回状态码 %d，%s 后重试 (%d/%d)", req.Method, req.URL.Path, resp.StatusCode, delay.Round(time.Millisecond), attempt+1, maxRetries)
+			io.Copy(io.Discard, io.LimitReader(resp.Body, maxErrorBodySize))
+			resp.Body.Close()
+		} else {
+			c.reporter.Infof("%s %s 失败: %v，%s 后重试 (%d/%d)", req.Method, req.URL.Path, err, delay.Round(time.Millisecond), attempt+1, maxRetries)
+		}
+		if err := sleepContext(req.Context(), delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// setBasicAuth 添加Basic认证头，只有identity token时不发送Basic认证
+func (c *Client) setBasicAuth(req *http.Request) {
+	if c.credentials == nil || c.credentials.Username == "" || c.credentials.Password == "" {
+		return
+	}
+	auth := base64.StdEncoding.EncodeToString([]byte(c.credentials.Username + ":" + c.credentials.Password))
+	req.Header.Set("Authorization", "Basic "+auth)
+}
+
+// cachedToken 返回指定scope下仍然有效的token
+func (c *Client) cachedToken(scope string) string {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+
+	cached, ok := c.tokens[scope]
+	if !ok || cached.Token == "" {
+		return ""
+	}
+	if time.Now().Add(tokenExpiryMargin).Unix() >= cached.Expires {
+		delete(c.tokens, scope)
+		return ""
+	}
+	return cached.Token
+}
+
+// storeToken 缓存指定scope的token，同时记录到当前认证信息中
+func (c *Client) storeToken(scope string, token *Credentials) {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+
+	if c.tokens == nil {
+		c.tokens = make(map[string]*Credentials)
+	}
+	c.tokens[scope] = token
+
+	if c.credentials != nil {
+		c.credentials.Token = token.Token
+		c.credentials.Expires = token.Expires
+	}
+}
+
+// errOAuthNotSupported 令牌服务不支持OAuth2 POST接口，此时改用GET请求获取token
+var errOAuthNotSupported = errors.New("令牌服务不支持OAuth2")
+
+// tokenResponse 令牌服务的响应，兼容 token 和 access_token 两种字段
+type tokenResponse struct {
+	Token        string `json:"token"`
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
+	IssuedAt     string `json:"issued_at"`
+}
+
+// fetchToken 从令牌服务获取指定scope的token
+// 有identity token时使用OAuth2 refresh_token授权，否则使用Basic认证的GET请求；
+// 登录时与 docker login 一样在GET请求中附带 offline_token=true，令牌服务支持时会返回refresh token
+func (c *Client) fetchToken(ctx context.Context, challenge *authChallenge, scope string) (*Credentials, error) {
+	realm := challenge.Parameters["realm"]
+	if realm == "" {
+		return nil, fmt.Errorf("认证质询缺少realm参数")
+	}
+
+	tokenURL, err := url.Parse(realm)
+	if err != nil {
+		return nil, fmt.Errorf("无效的realm地址: %v", err)
+	}
+	service := challenge.Parameters["service"]
+
+	if c.credentials != nil && c.credentials.IdentityToken != "" {
+		identityToken := c.credentials.IdentityToken
+		form := url.Values{}
+		form.Set("grant_type", "refresh_token")
+		form.Set("refresh_token", identityToken)
+		token, refreshToken, err := c.fetchOAuthToken(ctx, tokenURL, service, scope, form)
+		if errors.Is(err, errOAuthNotSupported) {
+			// 与 containerd 一致，令牌服务没有OAuth2 POST接口时使用identity token进行Basic认证的GET请求
+			secret := &Credentials{Username: c.credentials.Username, Password: identityToken}
+			token, _, err = c.fetchBasicToken(ctx, tokenURL, service, scope, secret, false)
+			return token, err
+		}
+		if err == nil && refreshToken != "" && refreshToken != identityToken {
+			c.updateIdentityToken(ctx, refreshToken)
+		}
+		return token, err
+	}
+
+	offline := c.offlineAccess && c.credentials != nil && c.credentials.Password != ""
+	token, refreshToken, err := c.fetchBasicToken(ctx, tokenURL, service, scope, c.credentials, offline)
+	if err == nil && offline && refreshToken != "" {
+		// 保存refresh token，登录后代替密码保存
+		c.credentials.IdentityToken = refreshToken
+	}
+	return token, err
+}
+
+// updateIdentityToken 令牌服务轮换了refresh token时更新认证信息并重新保存，旧的refresh token可能已经失效
+// 保存失败只报告诊断信息，本次命令仍然使用新的refresh token
+func (c *Client) updateIdentityToken(ctx context.Context, refreshToken string) {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+
+	c.credentials.IdentityToken = refreshToken
+	saved := c.savedCredentials()
+	if err := c.saveDockerCredentials(ctx, saved); err != nil {
+		c.reporter.Infof("保存新的refresh token失败: %v", err)
+	}
+	// 本地加密存储中已有该registry的凭证时一并更新
+	if store, err := c.credentialStore(); err == nil {
+		if _, err := store.Load(ctx, c.registryURL); err == nil {
+			if err := store.Save(ctx, c.registryURL, saved); err != nil {
+				c.reporter.Infof("保存新的refresh token失败: %v", err)
+			}
+		}
+	}
+}
+
+// fetchBasicToken 使用creds进行Basic认证，通过GET请求获取token，creds为nil时匿名请求
+// offline 为true时请求refresh token，令牌服务不支持时返回的refresh token为空
+func (c *Client) fetchBasicToken(ctx context.Context, tokenURL *url.URL, service, scope string, creds *Credentials, offline bool) (*Credentials, string, error) {
+	query := tokenURL.Query()
+	if service != "" {
+		query.Set("service", service)
+	}
+	for _, s := range strings.Fields(scope) {
+		query.Add("scope", s)
+	}
+	if creds != nil && creds.Username != "" {
+		query.Set("account", creds.Username)
+	}
+	if offline {
+		query.Set("offline_token", "true")
+		query.Set("client_id", oauthClientID)
+	}
+	getURL := *tokenURL
+	getURL.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", getURL.String(), nil)
+	if err != nil {
+		return nil, "", err
+	}
+	if creds != nil && creds.Username != "" && creds.Password != "" {
+		req.SetBasicAuth(creds.Username, creds.Password)
+	}
+
+	resp, err := c.send(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("令牌服务返回错误: %w", newResponseError(resp))
+	}
+
+	return parseTokenResponse(resp)
+}
+
+// fetchOAuthToken 通过OAuth2 POST接口获取token，返回access token和新的refresh token
+func (c *Client) fetchOAuthToken(ctx context.Context, tokenURL *url.URL, service, scope string, form url.Values) (*Credentials, string, error) {
+	form.Set("client_id", oauthClientID)
+	if service != "" {
+		form.Set("service", service)
+	}
+	if scope != "" {
+		form.Set("scope", scope)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", tokenURL.String(), strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.send(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, "", errOAuthNotSupported
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("令牌服务返回错误: %w", newResponseError(resp))
+	}
+
+	return parseTokenResponse(resp)
+}
+
+// parseTokenResponse 解析令牌服务的响应，计算token的过期时间
+func parseTokenResponse(resp *http.Response) (*Credentials, string, error) {
+	var tokenResp tokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
+		return nil, "", fmt.Errorf("解析令牌响应失败: %v", err)
+	}
+
+	token := tokenResp.Token
+	if token == "" {
+		token = tokenResp.AccessToken
+	}
+	if token == "" {
+		return nil, "", fmt.Errorf("令牌服务未返回token")
+	}
+
+	issuedAt := time.Now()
+	if tokenResp.IssuedAt != "" {
+		if t, err := time.Parse(time.RFC3339, tokenResp.IssuedAt); err == nil {
+			issuedAt = t
+		}
+	}
+	lifetime := defaultTokenLifetime
+	if tokenResp.ExpiresIn > 0 {
+		lifetime = time.Duration(tokenResp.ExpiresIn) * time.Second
+	}
+
+	return &Credentials{
+		Token:   token,
+		Expires: issuedAt.Add(lifetime).Unix(),
+	}, tokenResp.RefreshToken, nil
+}
+
+// scopeForPath 根据请求路径推断token的scope
+// /v2/<name>/manifests|blobs|tags/... -> repository:<name>:pull
+// /v2/_catalog -> registry:catalog:*
+func scopeForPath(path string) string {
+	path = strings.TrimPrefix(path, "/v2/")
+	if path == "" || path == "/" {
+		return ""
+	}
+	if path == "_catalog" {
+		return "registry:catalog:*"
+	}
+
+	for _, marker := range []string{"/manifests/", "/blobs/", "/tags/"} {
+		if idx := strings.LastIndex(path, marker); idx > 0 {
+			return fmt.Sprintf("repository:%s:pull", path[:idx])
+		}
+	}
+	return ""
+}
+
+// parseBearerChallenge 从 WWW-Authenticate 头中找出Bearer质询
+func parseBearerChallenge(headers []string) (*authChallenge, bool) {
+	for _, header := range headers {
+		challenge := parseAuthChallenge(header)
+		if challenge != nil && strings.EqualFold(challenge.Scheme, "bearer") {
+			return challenge, true
+		}
+	}
+	return nil, false
+}
+
+// parseAuthChallenge 解析形如 Bearer realm="...",service="...",scope="..." 的质询
+func parseAuthChallenge(header string) *authChallenge {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return nil
+	}
+
+	scheme, rest, _ := strings.Cut(header, " ")
+	challenge := &authChallenge{
+		Scheme:     scheme,
+		Parameters: make(map[string]string),
+	}
+
+	for {
+		rest = strings.TrimLeft(rest, " ,")
+		if rest == "" {
+			break
+		}
+
+		key, value, ok := strings.Cut(rest, "=")
+		if !ok {
+			break
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimLeft(value, " ")
+
+		// 参数值可能带引号，引号内允许出现逗号（如多个scope）
+		if strings.HasPrefix(value, "\"") {
+			end := 1
+			var sb strings.Builder
+			for end < len(value) && value[end] != '"' {
+				if value[end] == '\\' && end+1 < len(value) {
+					end++
+				}
+				sb.WriteByte(value[end])
+				end++
+			}
+			challenge.Parameters[key] = sb.String()
+			if end < len(value) {
+				end++
+			}
+			rest = value[end:]
+		} else {
+			v, remaining, _ := strings.Cut(value, ",")
+			challenge.Parameters[key] = strings.TrimSpace(v)
+			rest = remaining
+		}
+	}
+
+	return challenge
+}
@@ -0,0 +1,59 @@
+package registry
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseAuthChallenge(t *testing.T) {
+	tests := []struct {
+		header     string
+		scheme     string
+		parameters map[string]string
+	}{
+		{
+			`Bearer realm="https://auth.docker.io/token",service="registry.docker.io",scope="repository:library/php:pull"`,
+			"Bearer",
+			map[string]string{"realm": "https://auth.docker.io/token", "service": "registry.docker.io", "scope": "repository:library/php:pull"},
+		},
+		// 引号内的逗号和空格属于参数值
+		{
+			`Bearer realm="https://a/token",scope="repository:app:pull,push"`,
+			"Bearer",
+			map[string]string{"realm": "https://a/token", "scope": "repository:app:pull,push"},
+		},
+		{
+			`Bearer realm="https://a/token",scope="repository:a:pull repository:b:pull"`,
+			"Bearer",
+			map[string]string{"realm": "https://a/token", "scope": "repository:a:pull repository:b:pull"},
+		},
+		// 转义的引号
+		{`Bearer realm="a\"b\\c"`, "Bearer", map[string]string{"realm": `a"b\c`}},
+		// 不带引号的参数值，参数之间可以有空格
+		{`Bearer realm=https://a/token, service=reg`, "Bearer", map[string]string{"realm": "https://a/token", "service": "reg"}},
+		{`Bearer realm = "https://a/token" , service="reg"`, "Bearer", map[string]string{"realm": "https://a/token", "service": "reg"}},
+		// 参数名不区分大小写
+		{`Bearer Realm="x",SERVICE="y"`, "Bearer", map[string]string{"realm": "x", "service": "y"}},
+		{`Basic realm="Registry Realm"`, "Basic", map[string]string{"realm": "Registry Realm"}},
+		{`Bearer`, "Bearer", map[string]string{}},
+		// 缺少结束引号时取到末尾
+		{`Bearer realm="https://a/token`, "Bearer", map[string]string{"realm": "https://a/token"}},
+		{`  Bearer realm="x"  `, "Bearer", map[string]string{"realm": "x"}},
+	}
+	for _, tt := range tests {
+		challenge := parseAuthChallenge(tt.header)
+		if challenge == nil {
+			t.Errorf("parseAuthChallenge(%q) = nil", tt.header)
+			continue
+		}
+		if challenge.Scheme != tt.scheme || !reflect.DeepEqual(challenge.Parameters, tt.parameters) {
+			t.Errorf("parseAuthChallenge(%q) = %q %v，期望 %q %v", tt.header, challenge.Scheme, challenge.Parameters, tt.scheme, tt.parameters)
+		}
+	}
+
+	for _, header := range []string{"", "   "} {
+		if challenge := parseAuthChallenge(header); challenge != nil {
+			t.Errorf("parseAuthChallenge(%q) = %v，期望 nil", header, challenge)
+		}
+	}
+}
@@ -0,0 +1,280 @@
+package registry
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultCacheMaxSize 缓存默认的最大容量（256 MB）
+const DefaultCacheMaxSize int64 = 256 << 20
+
+// Cache 按内容摘要存储manifest和config blob的本地缓存
+// 以digest寻址的内容不可变，因此缓存项无需失效，只在超出容量时按最近访问时间淘汰
+type Cache struct {
+	dir     string
+	maxSize int64
+	mu      sync.Mutex
+
+	// size 缓存的总大小，第一次写入时扫描缓存目录得到，之后按写入和淘汰的字节数更新
+	size      int64
+	sizeKnown bool
+}
+
+// CacheInfo 表示缓存的统计信息
+type CacheInfo struct {
+	Dir     string
+	Entries int
+	Size    int64
+	MaxSize int64
+}
+
+// cacheEntry 表示缓存目录中的一个文件
+type cacheEntry struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// NewCache 创建位于 dir 的缓存，maxSize <= 0 时使用默认容量
+func NewCache(dir string, maxSize int64) *Cache {
+	if maxSize <= 0 {
+		maxSize = DefaultCacheMaxSize
+	}
+	return &Cache{dir: dir, maxSize: maxSize}
+}
+
+// SetCache 设置客户端使用的缓存，传入nil表示禁用缓存
+func (c *Client) SetCache(cache *Cache) {
+	c.cache = cache
+}
+
+// Dir 返回缓存目录
+func (cache *Cache) Dir() string {
+	return cache.dir
+}
+
+// Get 读取指定digest的内容，内容校验失败时视为未命中
+func (cache *Cache) Get(digest string) ([]byte, bool) {
+	path, err := cache.path(digest)
+	if err != nil {
+		return nil, false
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	if !verifyDigest(digest, data) {
+		os.Remove(path)
+		return nil, false
+	}
+
+	// 更新访问时间，用于按最近使用淘汰
+	now := time.Now()
+	os.Chtimes(path, now, now)
+	return data, true
+}
+
+// TagDigest 返回上次按标签获取manifest时记录的digest，没有记录时返回空字符串
+// ref 为标签对应的manifest地址，包含registry、仓库和标签
+func (cache *Cache) TagDigest(ref string) string {
+	data, err := os.ReadFile(cache.tagPath(ref))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// SetTagDigest 记录标签当前指向的digest
+// 记录只用于判断是否值得发送HEAD请求，不参与容量统计，digest仍然以HEAD请求的结果为准
+func (cache *Cache) SetTagDigest(ref, digest string) error {
+	path := cache.tagPath(ref)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(digest), 0600)
+}
+
+// Put 写入指定digest的内容，内容与digest不符时拒绝写入
+func (cache *Cache) Put(digest string, data []byte) error {
+	if !verifyDigest(digest, data) {
+		return fmt.Errorf("内容与摘要 %s 不匹配", digest)
+	}
+
+	path, err := cache.path(digest)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	// 先写临时文件再重命名，避免并发读到不完整的内容
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	// 覆盖已有的缓存项时总大小不变
+	var added int64
+	if _, err := os.Stat(path); err != nil {
+		added = int64(len(data))
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	// 只在总大小超过容量时才扫描并淘汰缓存项，避免每次写入都遍历缓存目录
+	if cache.grow(added) {
+		_, err = cache.Prune(cache.maxSize)
+	}
+	return err
+}
+
+// grow 记录新写入的字节数，返回总大小是否超过容量
+func (cache *Cache) grow(added int64) bool {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	if !cache.sizeKnown {
+		// 扫描结果已经包含刚写入的缓存项
+		entries, err := cache.entries()
+		if err != nil {
+			return true
+		}
+		cache.size = 0
+		for _, entry := range entries {
+			cache.size += entry.size
+		}
+		cache.sizeKnown = true
+	} else {
+		cache.size += added
+	}
+	return cache.size > cache.maxSize
+}
+
+// Info 返回缓存的统计信息
+func (cache *Cache) Info() (*CacheInfo, error) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	entries, err := cache.entries()
+	if err != nil {
+		return nil, err
+	}
+
+	info := &CacheInfo{
+		Dir:     cache.dir,
+		Entries: len(entries),
+		MaxSize: cache.maxSize,
+	}
+	for _, entry := range entries {
+		info.Size += entry.size
+	}
+	return info, nil
+}
+
+// Prune 按最近访问时间淘汰缓存项，直到总大小不超过 maxSize，返回释放的字节数
+// maxSize 为0时清空缓存，包括标签的记录
+func (cache *Cache) Prune(maxSize int64) (int64, error) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	if maxSize == 0 {
+		if err := os.RemoveAll(filepath.Join(cache.dir, "tags")); err != nil {
+			return 0, err
+		}
+	}
+
+	entries, err := cache.entries()
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+	for _, entry := range entries {
+		total += entry.size
+	}
+
+	// 最久未访问的排在前面
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].modTime.Before(entries[j].modTime)
+	})
+
+	var freed int64
+	for _, entry := range entries {
+		if total <= maxSize {
+			break
+		}
+		if err := os.Remove(entry.path); err != nil && !os.IsNotExist(err) {
+			return freed, err
+		}
+		total -= entry.size
+		freed += entry.size
+	}
+	cache.size, cache.sizeKnown = total, true
+	return freed, nil
+}
+
+// entries 列出缓存目录中的所有缓存项
+func (cache *Cache) entries() ([]cacheEntry, error) {
+	var entries []cacheEntry
+	err := filepath.Walk(filepath.Join(cache.dir, "blobs"), func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if info.IsDir() || strings.HasPrefix(info.Name(), ".tmp-") {
+			return nil
+		}
+		entries = append(entries, cacheEntry{
+			path:    path,
+			size:    info.Size(),
+			modTime: info.ModTime(),
+		})
+		return nil
+	})
+	return entries, err
+}
+
+// path 返回digest对应的缓存文件路径：<dir>/blobs/sha256/<hex>
+func (cache *Cache) path(digest string) (string, error) {
+	algorithm, hexDigest, ok := strings.Cut(digest, ":")
+	if !ok || algorithm != "sha256" || len(hexDigest) != sha256.Size*2 {
+		return "", fmt.Errorf("不支持的摘要格式: %s", digest)
+	}
+	if _, err := hex.DecodeString(hexDigest); err != nil {
+		return "", fmt.Errorf("不支持的摘要格式: %s", digest)
+	}
+	return filepath.Join(cache.dir, "blobs", algorithm, hexDigest), nil
+}
+
+// tagPath 返回标签记录的文件路径：<dir>/tags/<manifest地址的sha256>
+func (cache *Cache) tagPath(ref string) string {
+	sum := sha256.Sum256([]byte(ref))
+	return filepath.Join(cache.dir, "tags", hex.EncodeToString(sum[:]))
+}
+
+// verifyDigest 校验内容的sha256摘要
+func verifyDigest(digest string, data []byte) bool {
+	sum := sha256.Sum256(data)
+	return digest == "sha256:"+hex.EncodeToString(sum[:])
+}
@@ -1,15 +1,15 @@
 package registry
 
 import (
+	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
-	"os/exec"
 	"path/filepath"
-	"regexp"
-	"strings"
+	"sync"
 	"time"
 )
 
@@ -23,37 +23,116 @@ type Client struct {
 	registryURL string
 	httpClient  *http.Client
 	credentials *Credentials
+
+	// scheme 访问registry使用的协议，为空时自动确定
+	scheme   string
+	schemeMu sync.Mutex
+	// insecure 跳过TLS校验并允许回退到HTTP
+	insecure bool
+	// tlsFiles 额外的CA证书和客户端证书，transportErr 加载证书时的错误
+	tlsFiles     tlsFiles
+	transportErr error
+
+	// tokens 按scope缓存的Bearer token
+	tokens  map[string]*Credentials
+	tokenMu sync.Mutex
+	// offlineAccess 登录时向令牌服务请求refresh token
+	offlineAccess bool
+
+	// cache 按digest缓存manifest和config blob，为nil时不使用缓存
+	cache *Cache
+
+	// reporter 接收进度和诊断信息，默认不输出
+	reporter Reporter
+
+	// concurrency 获取元数据时的最大并发数量
+	concurrency  int
+	requestSlots chan struct{}
+
+	// dockerConfigDir Docker CLI的配置目录（config.json所在目录）
+	dockerConfigDir string
+	// configDir 插件自己的配置目录（本地凭证文件所在目录）
+	configDir string
 }
 
 // Credentials 表示认证信息
 type Credentials struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
-	Token    string `json:"token,omitempty"`
-	Expires  int64  `json:"expires,omitempty"`
+	// IdentityToken OAuth2 refresh token，用于向令牌服务换取访问令牌，存在时代替密码使用
+	IdentityToken string `json:"identitytoken,omitempty"`
+	// Token 最近一次从令牌服务获取的Bearer token
+	Token string `json:"token,omitempty"`
+	// Expires token的过期时间（Unix时间戳）
+	Expires int64 `json:"expires,omitempty"`
 }
 
 // Image 表示镜像信息
 type Image struct {
-	Repository string   `json:"repository"`
-	Tag        string   `json:"tag"`
-	Digest     string   `json:"digest"`
-	Size       string   `json:"size"`
-	Created    string   `json:"created"`
-	Platforms  []string `json:"platforms"`
+	Repository string   `json:"repository" yaml:"repository"`
+	Tag        string   `json:"tag" yaml:"tag"`
+	Digest     string   `json:"digest" yaml:"digest"`
+	Size       string   `json:"size" yaml:"size"`
+	Created    string   `json:"created" yaml:"created"`
+	Platforms  []string `json:"platforms" yaml:"platforms"`
+
+	// 排序和过滤使用的信息，不输出
+	platform  string
+	sizeBytes int64
+	tagCount  int
+}
+
+// ListOptions 镜像列表的查询选项
+type ListOptions struct {
+	// Platform 只列出支持该平台的镜像，为空时不过滤
+	Platform string
+	// PerPlatform 为true时多平台镜像的每个平台各占一行，大小和创建时间均为该平台的值
+	PerPlatform bool
+	// Sort 排序字段，为空时按仓库在registry目录中的顺序
+	Sort SortField
+	// Reverse 为true时按相反的顺序排列
+	Reverse bool
+	// Filter 过滤条件
+	Filter Filter
+	// Limit 最多返回的行数，0表示不限制
+	Limit int
+}
+
+// SearchOptions 搜索镜像的选项
+type SearchOptions struct {
+	// Platform 只搜索支持该平台的镜像，为空时不过滤
+	Platform string
+	// Limit 最多返回的行数（每个匹配的标签一行），在过滤和排序之后截断，0表示不限制
+	Limit int
+	// Sort 排序字段，为空时按仓库在registry目录中的顺序
+	Sort SortField
+	// Reverse 为true时按相反的顺序排列
+	Reverse bool
+	// Filter 过滤条件，按每个仓库的主要标签判断
+	Filter Filter
+	// IgnoreCase 为true时仓库名和标签的匹配忽略大小写
+	IgnoreCase bool
+	// Regex 为true时查询中的仓库名和标签按正则表达式匹配，而不是通配符
+	Regex bool
+	// Semver 标签的版本约束（如 >=8.1,<8.3），指定后查询中不能再包含标签部分
+	Semver string
 }
 
 // SearchResult 表示搜索结果
 type SearchResult struct {
-	Name        string   `json:"name"`
-	Description string   `json:"description"`
-	Tags        int      `json:"tags"`
-	Size        string   `json:"size"`
-	Platforms   []string `json:"platforms"`
-	Digest      string   `json:"digest"`
-	Created     string   `json:"created"`
-	LatestTag   string   `json:"latest_tag"`
-	MatchedTags []string `json:"matched_tags"`
+	Name        string   `json:"name" yaml:"name"`
+	Description string   `json:"description" yaml:"description"`
+	Tags        int      `json:"tags" yaml:"tags"`
+	Size        string   `json:"size" yaml:"size"`
+	Platforms   []string `json:"platforms" yaml:"platforms"`
+	Digest      string   `json:"digest" yaml:"digest"`
+	Created     string   `json:"created" yaml:"created"`
+	LatestTag   string   `json:"latest_tag" yaml:"latest_tag"`
+	MatchedTags []string `json:"matched_tags" yaml:"matched_tags"`
+
+	// 排序和过滤使用的信息，不输出
+	platform  string
+	sizeBytes int64
 }
 
 // Manifest 表示镜像清单
@@ -63,963 +142,545 @@ type Manifest struct {
 	Created string `json:"created"`
 }
 
-// NewClient 创建新的registry客户端
-func NewClient(registryURL string) *Client {
-	return &Client{
-		registryURL: registryURL,
+// NewClient 创建registry客户端，registryURL 可以带有协议（https 或 http），如 http://localhost:5000
+// 回环地址和Docker守护进程配置的 insecure-registries 默认视为不安全的registry
+func NewClient(registryURL string) (*Client, error) {
+	scheme, host := splitRegistryURL(registryURL)
+	if err := validateScheme(scheme); err != nil {
+		return nil, fmt.Errorf("无效的镜像源地址 %s: %v", registryURL, err)
+	}
+	c := &Client{
+		registryURL: host,
+		scheme:      scheme,
+		insecure:    IsInsecureRegistry(host),
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
+		reporter:        NopReporter{},
+		concurrency:     DefaultConcurrency,
+		requestSlots:    make(chan struct{}, DefaultConcurrency),
+		dockerConfigDir: DefaultDockerConfigDir(),
+		configDir:       DefaultConfigDir(),
 	}
+	c.configureTransport()
+	return c, nil
+}
+
+// SetDockerConfigDir 设置Docker CLI的配置目录，如 --config 参数指定的目录
+// 同时重新加载该目录下 certs.d 中的证书
+func (c *Client) SetDockerConfigDir(dir string) {
+	c.dockerConfigDir = dir
+	c.configureTransport()
+}
+
+// SetConfigDir 设置插件自己的配置目录
+func (c *Client) SetConfigDir(dir string) {
+	c.configDir = dir
 }
 
 // Login 登录到registry
-func (c *Client) Login(username, password string) error {
+func (c *Client) Login(ctx context.Context, username, password string) error {
 	// 构建认证URL
-	authURL := fmt.Sprintf("https://%s/v2/", c.registryURL)
-	
-	// 创建基本认证头
-	auth := base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
-	
-	req, err := http.NewRequest("GET", authURL, nil)
+	authURL := c.endpoint(ctx, "/v2/")
+
+	req, err := http.NewRequestWithContext(ctx, "GET", authURL, nil)
 	if err != nil {
 		return err
 	}
-	
-	req.Header.Set("Authorization", "Basic "+auth)
-	
-	resp, err := c.httpClient.Do(req)
+
+	// 使用待验证的认证信息发送请求，支持Basic认证和Bearer token认证
+	// 令牌服务支持OAuth2时同时请求refresh token
+	previous := c.credentials
+	c.credentials = &Credentials{
+		Username: username,
+		Password: password,
+	}
+	c.offlineAccess = true
+	defer func() { c.offlineAccess = false }()
+
+	resp, err := c.doRequest(req)
 	if err != nil {
+		c.credentials = previous
 		return err
 	}
 	defer resp.Body.Close()
-	
+
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("认证失败，状态码: %d", resp.StatusCode)
+		c.credentials = previous
+		return fmt.Errorf("认证失败: %w", newResponseError(resp))
 	}
-	
+
 	// 保存认证信息到Docker凭证存储
-	if err := c.saveDockerCredentials(username, password); err != nil {
+	if err := c.saveDockerCredentials(ctx, c.savedCredentials()); err != nil {
 		return fmt.Errorf("保存到Docker凭证存储失败: %v", err)
 	}
-	
-	// 同时保存到本地（向后兼容）
-	c.credentials = &Credentials{
-		Username: username,
-		Password: password,
-	}
-	
+
 	return nil
 }
 
-// SaveCredentials 保存认证信息到本地（向后兼容）
-func (c *Client) SaveCredentials(username, password string) error {
-	// 确保配置目录存在
-	configDir := os.Getenv("HOME") + "/.docker-genee"
-	if err := os.MkdirAll(configDir, 0700); err != nil {
-		return err
-	}
-	
-	credentials := &Credentials{
-		Username: username,
-		Password: password,
+// SaveCredentials 保存最近一次登录成功的认证信息到本地加密存储（系统密钥环或使用口令加密的文件）
+// 没有可用的加密存储时返回 ErrNoCredentialStore，不再保存明文密码
+func (c *Client) SaveCredentials(ctx context.Context) error {
+	if c.credentials == nil {
+		return fmt.Errorf("没有需要保存的认证信息")
 	}
-	
-	data, err := json.Marshal(credentials)
+
+	store, err := c.credentialStore()
 	if err != nil {
 		return err
 	}
-	
-	configFile := filepath.Join(configDir, "credentials.json")
-	return os.WriteFile(configFile, data, 0600)
-}
 
-// saveDockerCredentials 保存认证信息到Docker凭证存储
-func (c *Client) saveDockerCredentials(username, password string) error {
-	// 尝试多种方式保存到Docker凭证存储
-	
-	// 方法1: 使用docker-credential-helper
-	if err := c.saveCredentialsToHelper(username, password); err == nil {
-		return nil
-	}
-	
-	// 方法2: 保存到Docker配置文件
-	if err := c.saveCredentialsToDockerConfig(username, password); err == nil {
-		return nil
-	}
-	
-	return fmt.Errorf("无法保存到Docker凭证存储")
+	return store.Save(ctx, c.registryURL, c.savedCredentials())
 }
 
-// saveCredentialsToHelper 使用凭证助手保存凭证
-func (c *Client) saveCredentialsToHelper(username, password string) error {
-	// 尝试不同的凭证助手
-	helpers := []string{"docker-credential-helper", "docker-credential-desktop", "docker-credential-ecr-login"}
-	
-	for _, helper := range helpers {
-		if err := c.trySaveCredentialsHelper(helper, username, password); err == nil {
-			return nil
+// savedCredentials 返回需要持久化的认证信息
+// 令牌服务返回了refresh token时保存refresh token而不保存密码
+func (c *Client) savedCredentials() *Credentials {
+	if c.credentials.IdentityToken != "" {
+		return &Credentials{
+			Username:      c.credentials.Username,
+			IdentityToken: c.credentials.IdentityToken,
 		}
 	}
-	
-	return fmt.Errorf("无法使用凭证助手保存凭证")
-}
-
-// trySaveCredentialsHelper 尝试使用特定的凭证助手保存凭证
-func (c *Client) trySaveCredentialsHelper(helper, username, password string) error {
-	// 构建凭证数据
-	creds := map[string]string{
-		"Username": username,
-		"Secret":   password,
-	}
-	
-	// 序列化为JSON
-	data, err := json.Marshal(creds)
-	if err != nil {
-		return err
+	return &Credentials{
+		Username: c.credentials.Username,
+		Password: c.credentials.Password,
 	}
-	
-	// 使用凭证助手保存凭证
-	cmd := exec.Command(helper, "store")
-	cmd.Stdin = strings.NewReader(fmt.Sprintf("%s\n%s", c.registryURL, string(data)))
-	
-	return cmd.Run()
 }
 
-// saveCredentialsToDockerConfig 保存凭证到Docker配置文件
-func (c *Client) saveCredentialsToDockerConfig(username, password string) error {
-	configPath := os.Getenv("HOME") + "/.docker/config.json"
-	
-	// 读取现有配置
-	var config struct {
-		Auths map[string]struct {
-			Auth string `json:"auth"`
-		} `json:"auths"`
-	}
-	
-	if data, err := os.ReadFile(configPath); err == nil {
-		json.Unmarshal(data, &config)
-	}
-	
-	// 确保Auths字段存在
-	if config.Auths == nil {
-		config.Auths = make(map[string]struct {
-			Auth string `json:"auth"`
-		})
-	}
-	
-	// 编码认证信息
-	auth := base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
-	config.Auths[c.registryURL] = struct {
-		Auth string `json:"auth"`
-	}{
-		Auth: auth,
-	}
-	
-	// 保存配置
-	data, err := json.MarshalIndent(config, "", "  ")
+// saveDockerCredentials 保存认证信息到Docker凭证存储
+// 与Docker CLI一致：配置了凭证助手时保存到凭证助手，并在config.json中只保留不含密码的条目；
+// 否则以base64编码保存到config.json
+// identity token 在凭证助手中以用户名 <token> 保存，在config.json中保存到 identitytoken 字段
+func (c *Client) saveDockerCredentials(ctx context.Context, creds *Credentials) error {
+	config, err := loadDockerConfig(c.dockerConfigPath())
 	if err != nil {
 		return err
 	}
-	
-	// 确保目录存在
-	configDir := filepath.Dir(configPath)
-	if err := os.MkdirAll(configDir, 0700); err != nil {
-		return err
+
+	if helper := config.credentialHelper(c.registryURL); helper != "" {
+		if err := helperStore(ctx, helper, c.registryURL, creds); err != nil {
+			return err
+		}
+		config.setAuth(c.registryURL, dockerAuthConfig{})
+		return config.save()
 	}
-	
-	return os.WriteFile(configPath, data, 0600)
+
+	auth := base64.StdEncoding.EncodeToString([]byte(creds.Username + ":" + creds.Password))
+	config.setAuth(c.registryURL, dockerAuthConfig{Auth: auth, IdentityToken: creds.IdentityToken})
+	return config.save()
 }
 
 // LoadCredentials 从Docker凭证存储加载认证信息
-func (c *Client) LoadCredentials() error {
+func (c *Client) LoadCredentials(ctx context.Context) error {
 	// 尝试从Docker凭证存储获取认证信息
-	creds, err := c.getDockerCredentials()
+	creds, err := c.getDockerCredentials(ctx)
 	if err != nil {
 		// 如果无法从Docker获取，尝试从本地文件加载（向后兼容）
-		return c.loadLocalCredentials()
+		return c.loadLocalCredentials(ctx)
 	}
-	
+
 	c.credentials = creds
 	return nil
 }
 
 // getDockerCredentials 从Docker凭证存储获取认证信息
-func (c *Client) getDockerCredentials() (*Credentials, error) {
-	// 尝试多种方式获取Docker凭证
-	
-	// 方法1: 使用docker-credential-helper
-	if creds, err := c.getCredentialsFromHelper(); err == nil {
-		return creds, nil
-	}
-	
-	// 方法2: 从Docker配置文件读取
-	if creds, err := c.getCredentialsFromDockerConfig(); err == nil {
-		return creds, nil
-	}
-	
-	return nil, fmt.Errorf("无法从Docker获取凭证")
-}
-
-// getCredentialsFromHelper 使用docker-credential-helper获取凭证
-func (c *Client) getCredentialsFromHelper() (*Credentials, error) {
-	// 尝试不同的凭证助手
-	helpers := []string{"docker-credential-helper", "docker-credential-desktop", "docker-credential-ecr-login"}
-	
-	for _, helper := range helpers {
-		if creds, err := c.tryCredentialHelper(helper); err == nil {
-			return creds, nil
-		}
-	}
-	
-	return nil, fmt.Errorf("无法使用凭证助手获取凭证")
-}
-
-// tryCredentialHelper 尝试使用特定的凭证助手
-func (c *Client) tryCredentialHelper(helper string) (*Credentials, error) {
-	cmd := exec.Command(helper, "get")
-	cmd.Stdin = strings.NewReader(c.registryURL)
-	
-	output, err := cmd.Output()
+// 配置了凭证助手时只使用凭证助手，否则读取config.json中的 auths 条目
+func (c *Client) getDockerCredentials(ctx context.Context) (*Credentials, error) {
+	config, err := loadDockerConfig(c.dockerConfigPath())
 	if err != nil {
 		return nil, err
 	}
-	
-	// 解析输出格式：{"Username":"user","Secret":"pass"}
-	var creds struct {
-		Username string `json:"Username"`
-		Secret   string `json:"Secret"`
-	}
-	
-	if err := json.Unmarshal(output, &creds); err != nil {
-		return nil, err
+
+	if helper := config.credentialHelper(c.registryURL); helper != "" {
+		return helperGet(ctx, helper, c.registryURL)
 	}
-	
-	if creds.Username == "" || creds.Secret == "" {
-		return nil, fmt.Errorf("凭证信息不完整")
+
+	auth, ok := config.authConfig(c.registryURL)
+	if !ok {
+		return nil, fmt.Errorf("未找到对应的认证信息")
 	}
-	
-	return &Credentials{
-		Username: creds.Username,
-		Password: creds.Secret,
-	}, nil
+	return auth.credentials()
 }
 
-// getCredentialsFromDockerConfig 从Docker配置文件读取凭证
-func (c *Client) getCredentialsFromDockerConfig() (*Credentials, error) {
-	// 尝试从不同的Docker配置位置读取
-	configPaths := []string{
-		os.Getenv("HOME") + "/.docker/config.json",
-		"/etc/docker/config.json",
-	}
-	
-	for _, configPath := range configPaths {
-		if creds, err := c.readDockerConfig(configPath); err == nil {
-			return creds, nil
-		}
-	}
-	
-	return nil, fmt.Errorf("无法从Docker配置文件读取凭证")
+// dockerConfigPath 返回Docker配置文件的路径
+func (c *Client) dockerConfigPath() string {
+	return filepath.Join(c.dockerConfigDir, dockerConfigFileName)
 }
 
-// readDockerConfig 读取Docker配置文件
-func (c *Client) readDockerConfig(configPath string) (*Credentials, error) {
-	data, err := os.ReadFile(configPath)
-	if err != nil {
-		return nil, err
-	}
-	
-	var config struct {
-		Auths map[string]struct {
-			Auth string `json:"auth"`
-		} `json:"auths"`
-	}
-	
-	if err := json.Unmarshal(data, &config); err != nil {
-		return nil, err
-	}
-	
-	// 查找对应registry的认证信息
-	if auth, exists := config.Auths[c.registryURL]; exists && auth.Auth != "" {
-		// 解码base64认证信息
-		decoded, err := base64.StdEncoding.DecodeString(auth.Auth)
-		if err != nil {
-			return nil, err
+// localCredentialsPath 返回本地凭证文件的路径
+func (c *Client) localCredentialsPath() string {
+	return filepath.Join(c.configDir, "credentials.json")
+}
+
+// loadLocalCredentials 从本地加密存储加载认证信息
+// 加密存储不可用或其中没有凭证时，默认镜像源读取尚未迁移的明文凭证文件（向后兼容）
+func (c *Client) loadLocalCredentials(ctx context.Context) error {
+	store, err := c.credentialStore()
+	if err == nil {
+		var creds *Credentials
+		if creds, err = store.Load(ctx, c.registryURL); err == nil {
+			c.credentials = creds
+			return nil
 		}
-		
-		parts := strings.SplitN(string(decoded), ":", 2)
-		if len(parts) == 2 {
-			return &Credentials{
-				Username: parts[0],
-				Password: parts[1],
-			}, nil
+		if !errors.Is(err, errCredentialsNotFound) {
+			return err
 		}
 	}
-	
-	return nil, fmt.Errorf("未找到对应的认证信息")
-}
 
-// loadLocalCredentials 从本地文件加载认证信息（向后兼容）
-func (c *Client) loadLocalCredentials() error {
-	configFile := os.Getenv("HOME") + "/.docker-genee/credentials.json"
-	
-	data, err := os.ReadFile(configFile)
-	if err != nil {
+	if c.registryURL != legacyCredentialsRegistry {
+		return err
+	}
+	data, readErr := os.ReadFile(c.localCredentialsPath())
+	if readErr != nil {
 		return err
 	}
-	
 	return json.Unmarshal(data, &c.credentials)
 }
 
 // IsLoggedIn 检查是否已登录
-func (c *Client) IsLoggedIn() bool {
+func (c *Client) IsLoggedIn(ctx context.Context) bool {
 	if c.credentials == nil {
-		c.LoadCredentials()
+		c.LoadCredentials(ctx)
 	}
-	return c.credentials != nil && c.credentials.Username != ""
+	return c.credentials != nil && (c.credentials.Username != "" || c.credentials.IdentityToken != "")
 }
 
 // HasValidCredentials 检查是否有有效的认证信息
-func (c *Client) HasValidCredentials() bool {
+func (c *Client) HasValidCredentials(ctx context.Context) bool {
 	// 优先尝试从Docker凭证存储获取
-	if err := c.LoadCredentials(); err == nil && c.credentials != nil {
+	if err := c.LoadCredentials(ctx); err == nil && c.credentials != nil {
 		return true
 	}
 	return false
 }
 
 // ListImages 获取镜像列表
-func (c *Client) ListImages(platform string) ([]Image, error) {
+// 默认每个仓库一行，多平台镜像的大小和创建时间取自匹配 opts.Platform 的平台（未指定时为当前主机的平台）
+func (c *Client) ListImages(ctx context.Context, opts ListOptions) ([]Image, error) {
+	if err := validatePlatformFilter(opts.Platform); err != nil {
+		return nil, err
+	}
+
 	// 检查是否有有效的认证信息
-	if !c.HasValidCredentials() {
+	if !c.HasValidCredentials(ctx) {
 		return nil, fmt.Errorf("未找到有效的认证信息，请先使用 'docker genee login' 登录")
 	}
-	
+
 	// 确保认证信息已加载
 	if c.credentials == nil {
-		if err := c.LoadCredentials(); err != nil {
+		if err := c.LoadCredentials(ctx); err != nil {
 			return nil, fmt.Errorf("加载认证信息失败: %v", err)
 		}
 	}
-	
-	// 调用真实的registry API获取镜像列表，传入平台参数
-	images, err := c.fetchImagesFromRegistry(platform)
+
+	// 先解析过滤条件，since/before 指定的镜像不存在时不必获取整个列表
+	filter, err := c.resolveFilter(ctx, opts.Filter, opts.Platform)
 	if err != nil {
 		return nil, err
 	}
-	
-	return images, nil
-}
 
-// fetchImagesFromRegistry 从registry API获取镜像列表
-func (c *Client) fetchImagesFromRegistry(platform string) ([]Image, error) {
-	// 构建API URL
-	apiURL := fmt.Sprintf("https://%s/v2/_catalog", c.registryURL)
-	
-	// 创建请求
-	req, err := http.NewRequest("GET", apiURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("创建请求失败: %v", err)
-	}
-	
-	// 添加认证头
-	auth := base64.StdEncoding.EncodeToString([]byte(c.credentials.Username + ":" + c.credentials.Password))
-	req.Header.Set("Authorization", "Basic "+auth)
-	
-	// 发送请求
-	resp, err := c.httpClient.Do(req)
+	// 调用真实的registry API获取镜像列表，传入平台参数
+	images, err := c.fetchImagesFromRegistry(ctx, opts, filter)
 	if err != nil {
-		return nil, fmt.Errorf("请求失败: %v", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
-	
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API请求失败，状态码: %d", resp.StatusCode)
-	}
-	
-	// 解析响应
-	var catalog struct {
-		Repositories []string `json:"repositories"`
-	}
-	
-	if err := json.NewDecoder(resp.Body).Decode(&catalog); err != nil {
-		return nil, fmt.Errorf("解析响应失败: %v", err)
-	}
-	
-	fmt.Printf("找到 %d 个仓库，正在提取所有标签...\n", len(catalog.Repositories))
-	
-	// 获取每个仓库的镜像信息
-	var images []Image
-	for i, repo := range catalog.Repositories {
-		// 显示进度条
-		progress := float64(i+1) / float64(len(catalog.Repositories))
-		barWidth := 30
-		filled := int(progress * float64(barWidth))
-		bar := strings.Repeat("█", filled) + strings.Repeat("░", barWidth-filled)
-		fmt.Printf("\r进度: %s %d/%d", bar, i+1, len(catalog.Repositories))
-		
-		tags, err := c.getRepositoryTags(repo)
-		if err != nil {
-			continue
-		}
-		
-		if len(tags) == 0 {
-			continue
-		}
-		
-		// 如果指定了平台，检查仓库中是否有任何标签支持该平台
-		var supportedTags []string
-		if platform != "" {
-			// 检查所有标签，找出支持指定平台的标签
-			for _, tag := range tags {
-				tagPlatforms := c.GetImagePlatforms(repo, tag)
-				for _, imgPlatform := range tagPlatforms {
-					if strings.Contains(strings.ToLower(imgPlatform), strings.ToLower(platform)) {
-						supportedTags = append(supportedTags, tag)
-						break
-					}
-				}
-			}
-			
-			// 如果没有支持指定平台的标签，跳过这个仓库
-			if len(supportedTags) == 0 {
-				continue
-			}
-		}
-		
-		// 选择要显示的标签
-		var displayTag string
-		var displayPlatforms []string
-		
-		if platform != "" && len(supportedTags) > 0 {
-			// 从支持的标签中选择最佳的
-			displayTag = supportedTags[0]
-			// 优先选择 latest 标签
-			for _, tag := range supportedTags {
-				if tag == "latest" {
-					displayTag = tag
-					break
-				}
-			}
-			// 如果没有 latest，比较所有标签的时间戳，选择最新的
-			if displayTag == supportedTags[0] {
-				var latestTime time.Time
-				var latestTags []string
-				
-				// 遍历所有标签，找到时间戳最新的
-				for _, tag := range supportedTags {
-					manifest, err := c.getImageManifest(repo, tag)
-					if err == nil {
-						// 解析时间戳
-						if manifest.Created != "" {
-							// 尝试解析时间戳
-							if t, err := time.Parse(TimeFormat, manifest.Created); err == nil {
-								if latestTime.IsZero() || t.After(latestTime) {
-									latestTime = t
-									latestTags = []string{tag}
-								} else if t.Equal(latestTime) {
-									// 时间戳相同，添加到候选列表
-									latestTags = append(latestTags, tag)
-								}
-							}
-						}
-					}
-				}
-				
-				// 如果找到了时间戳最新的标签
-				if len(latestTags) > 0 {
-					if len(latestTags) == 1 {
-						// 只有一个最新标签
-						displayTag = latestTags[0]
-					} else {
-						// 多个标签时间戳相同，优先选择多架构标签
-						var multiArchTag string
-						for _, tag := range latestTags {
-							tagPlatforms := c.GetImagePlatforms(repo, tag)
-							if len(tagPlatforms) > 1 {
-								// 找到多架构标签，优先选择
-								multiArchTag = tag
-								break
-							}
-						}
-						
-						if multiArchTag != "" {
-							displayTag = multiArchTag
-						} else {
-							// 没有多架构标签，选择第一个
-							displayTag = latestTags[0]
-						}
-					}
-				}
-			}
-		} else {
-			// 没有平台过滤，选择最佳标签
-			displayTag = tags[0]
-			// 优先选择 latest 标签
-			for _, tag := range tags {
-				if tag == "latest" {
-					displayTag = tag
-					break
-				}
-			}
-			// 如果没有 latest，比较所有标签的时间戳，选择最新的
-			if displayTag == tags[0] {
-				var latestTime time.Time
-				var latestTags []string
-				
-				// 遍历所有标签，找到时间戳最新的
-				for _, tag := range tags {
-					manifest, err := c.getImageManifest(repo, tag)
-					if err == nil {
-						// 解析时间戳
-						if manifest.Created != "" {
-							// 尝试解析时间戳
-							if t, err := time.Parse(TimeFormat, manifest.Created); err == nil {
-								if latestTime.IsZero() || t.After(latestTime) {
-									latestTime = t
-									latestTags = []string{tag}
-								} else if t.Equal(latestTime) {
-									// 时间戳相同，添加到候选列表
-									latestTags = append(latestTags, tag)
-								}
-							}
-						}
-					}
-				}
-				
-				// 如果找到了时间戳最新的标签
-				if len(latestTags) > 0 {
-					if len(latestTags) == 1 {
-						// 只有一个最新标签
-						displayTag = latestTags[0]
-					} else {
-						// 多个标签时间戳相同，优先选择多架构标签
-						var multiArchTag string
-						for _, tag := range latestTags {
-							tagPlatforms := c.GetImagePlatforms(repo, tag)
-							if len(tagPlatforms) > 1 {
-								// 找到多架构标签，优先选择
-								multiArchTag = tag
-								break
-							}
-						}
-						
-						if multiArchTag != "" {
-							displayTag = multiArchTag
-						} else {
-							// 没有多架构标签，选择第一个
-							displayTag = latestTags[0]
-						}
-					}
-				}
-			}
-		}
-		
-		// 获取选中标签的平台信息
-		displayPlatforms = c.GetImagePlatforms(repo, displayTag)
-		
-		// 获取镜像详情
-		manifest, err := c.getImageManifest(repo, displayTag)
-		if err != nil {
-			continue
-		}
-		
-		images = append(images, Image{
-			Repository: repo,
-			Tag:        displayTag,
-			Digest:     manifest.Digest,
-			Size:       formatSize(manifest.Size),
-			Created:    manifest.Created,
-			Platforms:  displayPlatforms,
-		})
-	}
-	
-	// 清除进度条
-	fmt.Print("\r" + strings.Repeat(" ", 80) + "\r")
-	fmt.Printf("成功获取 %d 个镜像信息\n\n", len(images))
+
 	return images, nil
 }
 
-// getRepositoryTags 获取仓库的标签列表
-func (c *Client) getRepositoryTags(repository string) ([]string, error) {
-	apiURL := fmt.Sprintf("https://%s/v2/%s/tags/list", c.registryURL, repository)
-	
-	req, err := http.NewRequest("GET", apiURL, nil)
+// fetchImagesFromRegistry 从registry API获取镜像列表，并按 opts 过滤、排序和限制数量
+func (c *Client) fetchImagesFromRegistry(ctx context.Context, opts ListOptions, filter *listFilter) ([]Image, error) {
+	// 分页获取所有仓库
+	repositories, err := c.CatalogPages(ctx, 0, "").All()
 	if err != nil {
 		return nil, err
 	}
-	
-	// 添加认证头
-	auth := base64.StdEncoding.EncodeToString([]byte(c.credentials.Username + ":" + c.credentials.Password))
-	req.Header.Set("Authorization", "Basic "+auth)
-	
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-	
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("获取标签失败，状态码: %d", resp.StatusCode)
-	}
-	
-	var tags struct {
-		Tags []string `json:"tags"`
+
+	c.reporter.Infof("找到 %d 个仓库，正在提取所有标签...", len(repositories))
+
+	// 不需要过滤和排序时只获取前 opts.Limit 行所需的仓库
+	fetchLimit := opts.Limit
+	if filter != nil || opts.Sort != "" || opts.Reverse {
+		fetchLimit = 0
 	}
-	
-	if err := json.NewDecoder(resp.Body).Decode(&tags); err != nil {
+
+	// 并发获取每个仓库的镜像信息，结果按仓库顺序拼接以保证输出顺序稳定
+	bar := c.reporter.StartProgress("进度", len(repositories))
+	images := fetchRows(ctx, c, len(repositories), fetchLimit, func(Image) int { return 1 }, func(i int) []Image {
+		defer bar.Increment()
+		return c.getRepositoryImages(ctx, repositories[i], opts)
+	})
+
+	// 清除进度条
+	bar.Done()
+
+	// 操作被取消或超时时返回错误，不输出不完整的结果
+	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
-	
-	return tags.Tags, nil
-}
 
-// getImageManifest 获取镜像清单
-func (c *Client) getImageManifest(repository, tag string) (*Manifest, error) {
-	apiURL := fmt.Sprintf("https://%s/v2/%s/manifests/%s", c.registryURL, repository, tag)
-	
-	req, err := http.NewRequest("GET", apiURL, nil)
-	if err != nil {
+	images = filterRows(ctx, c, images, filter)
+	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
-	
-	// 添加认证头和Accept头
-	auth := base64.StdEncoding.EncodeToString([]byte(c.credentials.Username + ":" + c.credentials.Password))
-	req.Header.Set("Authorization", "Basic "+auth)
-	// 支持多种manifest格式，根据返回的Content-Type决定解析方法
-	req.Header.Set("Accept", "application/vnd.docker.distribution.manifest.v1+prettyjws, application/vnd.docker.distribution.manifest.list.v2+json, application/vnd.oci.image.index.v1+json, application/vnd.docker.distribution.manifest.v2+json, application/vnd.oci.image.manifest.v1+json")
-	
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, err
+	sortRows(images, opts.Sort, opts.Reverse)
+	if opts.Limit > 0 && len(images) > opts.Limit {
+		images = images[:opts.Limit]
 	}
-	defer resp.Body.Close()
-	
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("获取清单失败，状态码: %d", resp.StatusCode)
+
+	c.reporter.Infof("成功获取 %d 个镜像信息", len(images))
+	return images, nil
+}
+
+// getRepositoryImages 获取单个仓库中用于展示的镜像，按平台展开时每个平台一行，没有可展示的标签时返回nil
+func (c *Client) getRepositoryImages(ctx context.Context, repo string, opts ListOptions) []Image {
+	platform := opts.Platform
+	tags, err := c.getRepositoryTags(ctx, repo)
+	if err != nil {
+		return nil
 	}
-	
-	// 获取Digest头
-	digest := resp.Header.Get("Docker-Content-Digest")
-	if digest == "" {
-		digest = "unknown"
+
+	if len(tags) == 0 {
+		return nil
 	}
-	
-	// 获取响应的Content-Type
-	contentType := resp.Header.Get("Content-Type")
-	
-	// 解析清单获取大小和创建时间
-	var manifestData map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&manifestData); err != nil {
-		return nil, err
+	tagCount := len(tags)
+
+	// 如果指定了平台，检查仓库中是否有任何标签支持该平台
+	if platform != "" {
+		tags = c.filterTagsByPlatform(ctx, repo, tags, platform)
+
+		// 如果没有支持指定平台的标签，跳过这个仓库
+		if len(tags) == 0 {
+			return nil
+		}
 	}
-	
-	var totalSize int64
-	
-	// 检查是否是 OCI Image Index 或 Docker Manifest List
-	if mediaType, ok := manifestData["mediaType"].(string); ok {
-		if mediaType == "application/vnd.oci.image.index.v1+json" || mediaType == "application/vnd.docker.distribution.manifest.list.v2+json" {
-			// 多架构 manifest，需要获取每个架构的实际大小
-			if manifests, ok := manifestData["manifests"].([]interface{}); ok {
-				// 对于多架构镜像，我们选择第一个架构的大小作为代表
-				// 或者计算所有架构的平均大小
-				if len(manifests) > 0 {
-					if firstManifest, ok := manifests[0].(map[string]interface{}); ok {
-						if digest, ok := firstManifest["digest"].(string); ok {
-							// 获取第一个架构的 manifest 来获取实际大小
-							archSize := c.getArchitectureManifestSize(repository, digest)
-							totalSize = archSize
-						}
-					}
-				}
-			}
-		} else {
-			// 单架构 manifest
-			if config, ok := manifestData["config"].(map[string]interface{}); ok {
-				if size, ok := config["size"].(float64); ok {
-					totalSize += int64(size)
-				}
-			}
-			if layers, ok := manifestData["layers"].([]interface{}); ok {
-				for _, layer := range layers {
-					if layerMap, ok := layer.(map[string]interface{}); ok {
-						if size, ok := layerMap["size"].(float64); ok {
-							totalSize += int64(size)
-						}
-					}
-				}
+
+	// 选择要显示的标签
+	displayTag := c.selectBestTag(ctx, repo, tags, platform)
+
+	if opts.PerPlatform {
+		var images []Image
+		for _, info := range c.getTagPlatformDetails(ctx, repo, displayTag) {
+			if platform != "" && !matchesPlatform(info.Platform, platform) {
+				continue
 			}
+			images = append(images, Image{
+				Repository: repo,
+				Tag:        displayTag,
+				Digest:     info.Digest,
+				Size:       info.Size,
+				Created:    info.Created,
+				Platforms:  []string{info.Platform},
+				platform:   info.Platform,
+				sizeBytes:  info.sizeBytes,
+				tagCount:   tagCount,
+			})
 		}
+		return images
 	}
-	
-	// 根据实际的Content-Type获取创建时间
-	created := c.getImageCreatedTimeByContentType(repository, manifestData, contentType)
-	
-	return &Manifest{
-		Digest: digest,
-		Size:   totalSize,
-		Created: created,
-	}, nil
-}
 
-// getArchitectureManifestSize 获取单个架构 manifest 的大小
-func (c *Client) getArchitectureManifestSize(repository, digest string) int64 {
-	apiURL := fmt.Sprintf("https://%s/v2/%s/manifests/%s", c.registryURL, repository, digest)
-	
-	req, err := http.NewRequest("GET", apiURL, nil)
-	if err != nil {
-		return 0
-	}
-	
-	// 添加认证头
-	auth := base64.StdEncoding.EncodeToString([]byte(c.credentials.Username + ":" + c.credentials.Password))
-	req.Header.Set("Authorization", "Basic "+auth)
-	// 支持多种manifest格式
-	req.Header.Set("Accept", "application/vnd.docker.distribution.manifest.v1+prettyjws, application/vnd.docker.distribution.manifest.list.v2+json, application/vnd.oci.image.index.v1+json, application/vnd.docker.distribution.manifest.v2+json, application/vnd.oci.image.manifest.v1+json")
-	
-	resp, err := c.httpClient.Do(req)
+	// 获取选中标签的平台信息
+	displayPlatforms := c.GetImagePlatforms(ctx, repo, displayTag)
+
+	// 获取镜像详情
+	manifest, err := c.getImageManifest(ctx, repo, displayTag, platform)
 	if err != nil {
-		return 0
+		return nil
 	}
-	defer resp.Body.Close()
-	
-	if resp.StatusCode != http.StatusOK {
-		return 0
+
+	return []Image{{
+		Repository: repo,
+		Tag:        displayTag,
+		Digest:     manifest.Digest,
+		Size:       FormatSize(manifest.Size),
+		Created:    manifest.Created,
+		Platforms:  displayPlatforms,
+		platform:   platform,
+		sizeBytes:  manifest.Size,
+		tagCount:   tagCount,
+	}}
+}
+
+// filterTagsByPlatform 并发检查所有标签，保留支持指定平台的标签（保持原有顺序）
+func (c *Client) filterTagsByPlatform(ctx context.Context, repository string, tags []string, platform string) []string {
+	supported := make([]bool, len(tags))
+	c.runConcurrently(ctx, len(tags), func(i int) {
+		for _, imgPlatform := range c.GetImagePlatforms(ctx, repository, tags[i]) {
+			if matchesPlatform(imgPlatform, platform) {
+				supported[i] = true
+				break
+			}
+		}
+	})
+
+	var supportedTags []string
+	for i, tag := range tags {
+		if supported[i] {
+			supportedTags = append(supportedTags, tag)
+		}
 	}
-	
-	// 解析 manifest 获取大小
-	var manifestData map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&manifestData); err != nil {
-		return 0
+	return supportedTags
+}
+
+// selectBestTag 选择最佳标签：优先 latest，其次创建时间最新的标签，
+// 时间相同时优先多架构标签；多架构标签的创建时间取 platform 对应的平台
+func (c *Client) selectBestTag(ctx context.Context, repository string, tags []string, platform string) string {
+	// 优先选择 latest 标签
+	for _, tag := range tags {
+		if tag == "latest" {
+			return tag
+		}
 	}
-	
-	var totalSize int64
-	
-	// 获取 config 大小
-	if config, ok := manifestData["config"].(map[string]interface{}); ok {
-		if size, ok := config["size"].(float64); ok {
-			totalSize += int64(size)
+
+	// 并发获取所有标签的创建时间
+	createdTimes := make([]time.Time, len(tags))
+	c.runConcurrently(ctx, len(tags), func(i int) {
+		manifest, err := c.getImageManifest(ctx, repository, tags[i], platform)
+		if err != nil || manifest.Created == "" {
+			return
+		}
+		// 尝试解析时间戳
+		if t, err := time.Parse(TimeFormat, manifest.Created); err == nil {
+			createdTimes[i] = t
 		}
+	})
+
+	// 遍历所有标签，找到时间戳最新的
+	var latestTime time.Time
+	var latestTags []string
+	for i, tag := range tags {
+		t := createdTimes[i]
+		if t.IsZero() {
+			continue
+		}
+		if latestTime.IsZero() || t.After(latestTime) {
+			latestTime = t
+			latestTags = []string{tag}
+		} else if t.Equal(latestTime) {
+			// 时间戳相同，添加到候选列表
+			latestTags = append(latestTags, tag)
+		}
+	}
+
+	// 没有可用的时间戳，选择第一个标签
+	if len(latestTags) == 0 {
+		return tags[0]
 	}
-	
-	// 获取 layers 大小
-	if layers, ok := manifestData["layers"].([]interface{}); ok {
-		for _, layer := range layers {
-			if layerMap, ok := layer.(map[string]interface{}); ok {
-				if size, ok := layerMap["size"].(float64); ok {
-					totalSize += int64(size)
-				}
+
+	// 多个标签时间戳相同，优先选择多架构标签
+	if len(latestTags) > 1 {
+		for _, tag := range latestTags {
+			if len(c.GetImagePlatforms(ctx, repository, tag)) > 1 {
+				return tag
 			}
 		}
 	}
-	
-	return totalSize
+
+	return latestTags[0]
 }
 
-// getImageCreatedTimeByContentType 根据Content-Type从manifest中获取镜像的创建时间
-func (c *Client) getImageCreatedTimeByContentType(repository string, manifestData map[string]interface{}, contentType string) string {
-	// 根据实际的Content-Type决定解析方法
-	switch contentType {
-	case "application/vnd.docker.distribution.manifest.v1+prettyjws":
-		// Docker v1格式：从history中获取创建时间
-		return c.getV1CreatedTime(manifestData)
-	case "application/vnd.docker.distribution.manifest.v2+json":
-		// Docker v2格式：从config blob中获取创建时间
-		return c.getV2CreatedTime(repository, manifestData)
-	case "application/vnd.oci.image.manifest.v1+json":
-		// OCI格式：从config blob中获取创建时间
-		return c.getV2CreatedTime(repository, manifestData)
-	case "application/vnd.docker.distribution.manifest.list.v2+json", "application/vnd.oci.image.index.v1+json":
-		// 多架构manifest：从第一个架构获取时间
-		return c.getMultiArchCreatedTime(repository, manifestData)
-	}
-	
-	// 如果没有mediaType，尝试从history字段判断是否为v1格式
-	if history, ok := manifestData["history"].([]interface{}); ok && len(history) > 0 {
-		return c.getV1CreatedTime(manifestData)
-	}
-	
-	// 如果无法获取创建时间，返回当前时间（向后兼容）
-	now := time.Now()
-	return now.Format(TimeFormat)
+// getRepositoryTags 获取仓库的标签列表
+func (c *Client) getRepositoryTags(ctx context.Context, repository string) ([]string, error) {
+	// 分页获取所有标签
+	return c.TagPages(ctx, repository, 0, "").All()
 }
 
-// getImageCreatedTime 从manifest中获取镜像的创建时间（向后兼容）
-func (c *Client) getImageCreatedTime(repository string, manifestData map[string]interface{}) string {
-	// 根据manifest的mediaType决定解析方法
-	if mediaType, ok := manifestData["mediaType"].(string); ok {
-		switch mediaType {
-		case "application/vnd.docker.distribution.manifest.v1+prettyjws":
-			// Docker v1格式：从history中获取创建时间
-			return c.getV1CreatedTime(manifestData)
-		case "application/vnd.docker.distribution.manifest.v2+json":
-			// Docker v2格式：从config blob中获取创建时间
-			return c.getV2CreatedTime(repository, manifestData)
-		case "application/vnd.oci.image.manifest.v1+json":
-			// OCI格式：从config blob中获取创建时间
-			return c.getV2CreatedTime(repository, manifestData)
-		case "application/vnd.docker.distribution.manifest.list.v2+json", "application/vnd.oci.image.index.v1+json":
-			// 多架构manifest：从第一个架构获取时间
-			return c.getMultiArchCreatedTime(repository, manifestData)
-		}
+// getImageManifest 获取镜像清单
+// 多架构镜像的大小和创建时间取自 platform 对应的平台，platform 为空时优先当前主机的平台；
+// 无法获取创建时间时 Created 为空
+func (c *Client) getImageManifest(ctx context.Context, repository, tag, platform string) (*Manifest, error) {
+	manifest, err := c.getManifest(ctx, repository, tag)
+	if err != nil {
+		return nil, err
 	}
-	
-	// 如果没有mediaType，尝试从history字段判断是否为v1格式
-	if history, ok := manifestData["history"].([]interface{}); ok && len(history) > 0 {
-		return c.getV1CreatedTime(manifestData)
+
+	// 获取Digest
+	digest := manifest.Digest
+	if digest == "" {
+		digest = "unknown"
 	}
-	
-	// 如果无法获取创建时间，返回当前时间（向后兼容）
-	now := time.Now()
-	return now.Format(TimeFormat)
-}
 
-// getV1CreatedTime 从Docker v1格式manifest获取创建时间
-func (c *Client) getV1CreatedTime(manifestData map[string]interface{}) string {
-	if history, ok := manifestData["history"].([]interface{}); ok && len(history) > 0 {
-		// 获取第一个history条目（最新的层）
-		if firstHistory, ok := history[0].(map[string]interface{}); ok {
-			if v1Compat, ok := firstHistory["v1Compatibility"].(string); ok {
-				// 解析v1Compatibility JSON字符串
-				var v1Data map[string]interface{}
-				if err := json.Unmarshal([]byte(v1Compat), &v1Data); err == nil {
-					if created, ok := v1Data["created"].(string); ok && created != "" {
-						// 尝试解析时间戳
-						if t, err := time.Parse(time.RFC3339, created); err == nil {
-							return t.Format(TimeFormat)
-						}
-						// 如果RFC3339格式失败，尝试其他格式
-						if t, err := time.Parse("2006-01-02T15:04:05Z", created); err == nil {
-							return t.Format(TimeFormat)
-						}
-						// 如果已经是本地时间格式，直接返回
-						if len(created) >= 19 {
-							return created[:19]
-						}
-					}
-				}
-			}
+	var totalSize int64
+	var created string
+	switch {
+	case manifest.Index != nil:
+		// 多架构 manifest，使用选中平台的大小和创建时间
+		descriptor, err := selectPlatformManifest(manifest.Index, platform)
+		if err != nil {
+			// 没有匹配的平台时退回当前主机的平台
+			descriptor, err = selectPlatformManifest(manifest.Index, "")
 		}
+		if err != nil {
+			break
+		}
+		totalSize, created = c.getArchitectureDetails(ctx, repository, descriptor.Digest)
+	case manifest.Manifest != nil:
+		// 单架构 manifest
+		totalSize = manifest.Manifest.TotalSize()
+		created = c.getImageCreatedTime(ctx, repository, manifest)
+	default:
+		// 根据实际的媒体类型获取创建时间
+		created = c.getImageCreatedTime(ctx, repository, manifest)
 	}
-	return time.Now().Format(TimeFormat)
+
+	return &Manifest{
+		Digest:  digest,
+		Size:    totalSize,
+		Created: created,
+	}, nil
 }
 
-// getV2CreatedTime 从Docker v2/OCI格式manifest获取创建时间
-func (c *Client) getV2CreatedTime(repository string, manifestData map[string]interface{}) string {
-	if config, ok := manifestData["config"].(map[string]interface{}); ok {
-		if digest, ok := config["digest"].(string); ok {
-			// 获取config blob来解析创建时间
-			return c.getConfigCreatedTime(repository, digest)
-		}
+// getArchitectureDetails 获取单个架构 manifest 的压缩大小和创建时间
+func (c *Client) getArchitectureDetails(ctx context.Context, repository, digest string) (int64, string) {
+	manifest, err := c.getManifest(ctx, repository, digest)
+	if err != nil || manifest.Manifest == nil {
+		return 0, ""
 	}
-	return time.Now().Format(TimeFormat)
+	return manifest.Manifest.TotalSize(), c.getConfigCreatedTime(ctx, repository, manifest.Manifest.Config.Digest)
 }
 
-// getMultiArchCreatedTime 从多架构manifest获取创建时间
-func (c *Client) getMultiArchCreatedTime(repository string, manifestData map[string]interface{}) string {
-	if manifests, ok := manifestData["manifests"].([]interface{}); ok && len(manifests) > 0 {
-		if firstManifest, ok := manifests[0].(map[string]interface{}); ok {
-			if digest, ok := firstManifest["digest"].(string); ok {
-				// 获取第一个架构的manifest来获取创建时间
-				return c.getArchitectureCreatedTime(repository, digest)
-			}
-		}
+// getImageCreatedTime 根据manifest的媒体类型获取镜像的创建时间
+func (c *Client) getImageCreatedTime(ctx context.Context, repository string, manifest *FetchedManifest) string {
+	switch {
+	case manifest.V1 != nil:
+		// Docker v1格式：从history中获取创建时间
+		return getV1CreatedTime(manifest.V1)
+	case manifest.Manifest != nil:
+		// Docker v2/OCI格式：从config blob中获取创建时间
+		return c.getConfigCreatedTime(ctx, repository, manifest.Manifest.Config.Digest)
 	}
-	return time.Now().Format(TimeFormat)
+
+	// 无法获取创建时间时返回空字符串，排序和过滤时视为未知
+	return ""
 }
 
-// getArchitectureCreatedTime 获取单个架构manifest的创建时间
-func (c *Client) getArchitectureCreatedTime(repository, digest string) string {
-	apiURL := fmt.Sprintf("https://%s/v2/%s/manifests/%s", c.registryURL, repository, digest)
-	
-	req, err := http.NewRequest("GET", apiURL, nil)
-	if err != nil {
-		return time.Now().Format(TimeFormat)
-	}
-	
-	// 添加认证头
-	auth := base64.StdEncoding.EncodeToString([]byte(c.credentials.Username + ":" + c.credentials.Password))
-	req.Header.Set("Authorization", "Basic "+auth)
-	// 支持多种manifest格式
-	req.Header.Set("Accept", "application/vnd.docker.distribution.manifest.v1+prettyjws, application/vnd.docker.distribution.manifest.list.v2+json, application/vnd.oci.image.index.v1+json, application/vnd.docker.distribution.manifest.v2+json, application/vnd.oci.image.manifest.v1+json")
-	
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return time.Now().Format(TimeFormat)
-	}
-	defer resp.Body.Close()
-	
-	if resp.StatusCode != http.StatusOK {
-		return time.Now().Format(TimeFormat)
-	}
-	
-	// 解析manifest
-	var manifestData map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&manifestData); err != nil {
-		return time.Now().Format(TimeFormat)
-	}
-	
-	// 从config中获取创建时间
-	if config, ok := manifestData["config"].(map[string]interface{}); ok {
-		if digest, ok := config["digest"].(string); ok {
-			// 获取config blob来解析创建时间
-			return c.getConfigCreatedTime(repository, digest)
+// getV1CreatedTime 从Docker v1格式manifest获取创建时间
+func getV1CreatedTime(manifest *SchemaV1Manifest) string {
+	// 第一个history条目对应最新的层
+	if images := manifest.v1Images(); len(images) > 0 {
+		if created, ok := formatCreated(images[0].Created); ok {
+			return created
 		}
 	}
-	
-	return time.Now().Format(TimeFormat)
+	return ""
 }
 
 // getConfigCreatedTime 从config blob获取创建时间
-func (c *Client) getConfigCreatedTime(repository, digest string) string {
-	apiURL := fmt.Sprintf("https://%s/v2/%s/blobs/%s", c.registryURL, repository, digest)
-	
-	req, err := http.NewRequest("GET", apiURL, nil)
-	if err != nil {
-		return time.Now().Format(TimeFormat)
-	}
-	
-	// 添加认证头
-	auth := base64.StdEncoding.EncodeToString([]byte(c.credentials.Username + ":" + c.credentials.Password))
-	req.Header.Set("Authorization", "Basic "+auth)
-	
-	resp, err := c.httpClient.Do(req)
+func (c *Client) getConfigCreatedTime(ctx context.Context, repository, digest string) string {
+	config, err := c.getImageConfig(ctx, repository, digest)
 	if err != nil {
-		return time.Now().Format(TimeFormat)
-	}
-	defer resp.Body.Close()
-	
-	if resp.StatusCode != http.StatusOK {
-		return time.Now().Format(TimeFormat)
-	}
-	
-	// 解析config blob
-	var configData map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&configData); err != nil {
-		return time.Now().Format(TimeFormat)
+		return ""
 	}
-	
-	// 从config中获取创建时间
-	if created, ok := configData["created"].(string); ok && created != "" {
-		// 尝试解析时间戳
-		if t, err := time.Parse(time.RFC3339, created); err == nil {
-			return t.Format(TimeFormat)
-		}
-		// 如果RFC3339格式失败，尝试其他格式
-		if t, err := time.Parse("2006-01-02T15:04:05Z", created); err == nil {
-			return t.Format(TimeFormat)
-		}
-		// 如果已经是本地时间格式，直接返回
-		if len(created) >= 19 {
-			return created[:19]
-		}
+
+	if created, ok := formatCreated(config.Created); ok {
+		return created
 	}
-	
-	return time.Now().Format(TimeFormat)
+	return ""
 }
 
-// formatSize 格式化大小
-func formatSize(bytes int64) string {
+// FormatSize 格式化大小
+func FormatSize(bytes int64) string {
 	const unit = 1024
 	if bytes < unit {
 		return fmt.Sprintf("%d B", bytes)
@@ -1033,158 +694,103 @@ func formatSize(bytes int64) string {
 }
 
 // SearchImages 搜索镜像
-func (c *Client) SearchImages(query, platform string, limit int) ([]SearchResult, error) {
+// query 的格式为 repository[:tag]，两部分都支持通配符（见 Glob），也可以按 opts 使用正则表达式或版本约束
+func (c *Client) SearchImages(ctx context.Context, query string, opts SearchOptions) ([]SearchResult, error) {
+	if err := validatePlatformFilter(opts.Platform); err != nil {
+		return nil, err
+	}
+	q, err := parseSearchQuery(query, opts)
+	if err != nil {
+		return nil, err
+	}
+
 	// 检查是否有有效的认证信息
-	if !c.HasValidCredentials() {
+	if !c.HasValidCredentials(ctx) {
 		return nil, fmt.Errorf("未找到有效的认证信息，请先使用 'docker genee login' 登录")
 	}
-	
+
 	// 确保认证信息已加载
 	if c.credentials == nil {
-		if err := c.LoadCredentials(); err != nil {
+		if err := c.LoadCredentials(ctx); err != nil {
 			return nil, fmt.Errorf("加载认证信息失败: %v", err)
 		}
 	}
-	
+
+	filter, err := c.resolveFilter(ctx, opts.Filter, opts.Platform)
+	if err != nil {
+		return nil, err
+	}
+
 	// 调用真实的registry API进行搜索
-	return c.searchImagesFromRegistry(query, platform, limit)
+	return c.searchImagesFromRegistry(ctx, q, opts, filter)
 }
 
-// searchImagesFromRegistry 从registry API搜索镜像
-func (c *Client) searchImagesFromRegistry(query, platform string, limit int) ([]SearchResult, error) {
-	// 首先获取所有仓库
-	apiURL := fmt.Sprintf("https://%s/v2/_catalog", c.registryURL)
-	
-	req, err := http.NewRequest("GET", apiURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("创建请求失败: %v", err)
-	}
-	
-	// 添加认证头
-	auth := base64.StdEncoding.EncodeToString([]byte(c.credentials.Username + ":" + c.credentials.Password))
-	req.Header.Set("Authorization", "Basic "+auth)
-	
-	resp, err := c.httpClient.Do(req)
+// searchImagesFromRegistry 从registry API搜索镜像，并按 opts 过滤、排序和限制数量
+func (c *Client) searchImagesFromRegistry(ctx context.Context, q *searchQuery, opts SearchOptions, filter *listFilter) ([]SearchResult, error) {
+	// 首先分页获取所有仓库
+	repositories, err := c.CatalogPages(ctx, 0, "").All()
 	if err != nil {
-		return nil, fmt.Errorf("请求失败: %v", err)
-	}
-	defer resp.Body.Close()
-	
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API请求失败，状态码: %d", resp.StatusCode)
-	}
-	
-	// 解析响应
-	var catalog struct {
-		Repositories []string `json:"repositories"`
-	}
-	
-	if err := json.NewDecoder(resp.Body).Decode(&catalog); err != nil {
-		return nil, fmt.Errorf("解析响应失败: %v", err)
+		return nil, err
 	}
-	
-	// 过滤匹配的仓库和标签
+
+	// 过滤匹配的仓库
 	var matchedRepos []string
-	var tagPatterns []string
-	for _, repo := range catalog.Repositories {
-		matched, tagPattern := matchesQuery(repo, query)
-		if matched {
+	for _, repo := range repositories {
+		if q.repository.Match(repo) {
 			matchedRepos = append(matchedRepos, repo)
-			if len(tagPattern) > 0 {
-				tagPatterns = append(tagPatterns, tagPattern[0])
-			} else {
-				tagPatterns = append(tagPatterns, "")
-			}
 		}
 	}
-	
-	// 限制结果数量
-	if len(matchedRepos) > limit {
-		matchedRepos = matchedRepos[:limit]
-	}
-	
-	// 构建搜索结果
-	var results []SearchResult
-	for i, repo := range matchedRepos {
-		// 显示进度条
-		progress := float64(i+1) / float64(len(matchedRepos))
-		barWidth := 30
-		filled := int(progress * float64(barWidth))
-		bar := strings.Repeat("█", filled) + strings.Repeat("░", barWidth-filled)
-		fmt.Printf("\r搜索进度: %s %d/%d", bar, i+1, len(matchedRepos))
-		
+
+	// 仓库中可能没有匹配的标签，数量限制要在标签过滤之后按行计算；
+	// 不需要过滤和排序时只获取前 opts.Limit 行所需的仓库
+	fetchLimit := opts.Limit
+	if filter != nil || opts.Sort != "" || opts.Reverse {
+		fetchLimit = 0
+	}
+
+	// 并发构建搜索结果，结果按仓库顺序拼接以保证输出顺序稳定
+	bar := c.reporter.StartProgress("搜索进度", len(matchedRepos))
+	results := fetchRows(ctx, c, len(matchedRepos), fetchLimit, SearchResult.rowCount, func(i int) []SearchResult {
+		defer bar.Increment()
 		// 获取仓库信息，传入标签模式、平台过滤和标签列表进行匹配
-		repoInfo, err := c.getRepositoryInfoWithFilters(repo, tagPatterns[i], platform)
+		repoInfo, err := c.getRepositoryInfoWithFilters(ctx, matchedRepos[i], q.tag, opts.Platform)
 		if err != nil {
-			continue
+			return nil
 		}
-		
-		results = append(results, *repoInfo)
-	}
-	
+		return []SearchResult{*repoInfo}
+	})
+
 	// 清除进度条
-	fmt.Print("\r" + strings.Repeat(" ", 80) + "\r")
-	
-	return results, nil
-}
+	bar.Done()
 
-// matchesQuery 检查仓库名和标签是否匹配查询
-func matchesQuery(repository, query string) (bool, []string) {
-	// 检查是否包含冒号分隔符（格式：repository:tag_pattern）
-	if strings.Contains(query, ":") {
-		parts := strings.SplitN(query, ":", 2)
-		if len(parts) == 2 {
-			repoPattern := parts[0]
-			tagPattern := parts[1]
-			
-			// 调试信息（可选）
-			// if strings.Contains(repository, "node") {
-			// 	fmt.Printf("DEBUG: checking repo '%s' against pattern '%s'\n", repository, repoPattern)
-			// }
-			
-			// 检查仓库名是否匹配
-			repoMatched := false
-			if strings.Contains(repoPattern, "*") {
-				pattern := strings.ReplaceAll(repoPattern, "*", ".*")
-				repoMatched, _ = regexp.MatchString(pattern, repository)
-			} else {
-				repoMatched = strings.Contains(strings.ToLower(repository), strings.ToLower(repoPattern))
-			}
-			
-			if !repoMatched {
-				return false, nil
-			}
-			
-			// 如果仓库匹配，返回true，标签模式将在后续处理中匹配
-			return true, []string{tagPattern}
-		}
+	// 操作被取消或超时时返回错误，不输出不完整的结果
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
-	
-	// 没有冒号分隔符，只匹配仓库名
-	if strings.Contains(query, "*") {
-		pattern := strings.ReplaceAll(query, "*", ".*")
-		matched, _ := regexp.MatchString(pattern, repository)
-		return matched, nil
+
+	results = filterRows(ctx, c, results, filter)
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
-	
-	// 精确匹配仓库名
-	return repository == query, nil
+	sortRows(results, opts.Sort, opts.Reverse)
+
+	return limitSearchResults(results, opts.Limit), nil
 }
 
 // getRepositoryInfoWithFilters 获取仓库信息，支持标签和平台过滤
-func (c *Client) getRepositoryInfoWithFilters(repository, tagPattern, platformFilter string) (*SearchResult, error) {
+// tagPattern 为版本约束时，匹配的标签按版本号从新到旧排列
+func (c *Client) getRepositoryInfoWithFilters(ctx context.Context, repository string, tagPattern matcher, platformFilter string) (*SearchResult, error) {
 	// 获取标签列表
-	tags, err := c.getRepositoryTags(repository)
+	tags, err := c.getRepositoryTags(ctx, repository)
 	if err != nil {
 		return nil, err
 	}
-	
+
 	// 步骤1: 如果有标签模式，先过滤出符合要求的标签
 	var filteredTags []string
-	if tagPattern != "" {
+	if tagPattern != nil {
 		for _, tag := range tags {
-			matched := matchesTagPattern(tag, tagPattern)
-			if matched {
+			if tagPattern.Match(tag) {
 				filteredTags = append(filteredTags, tag)
 			}
 		}
@@ -1192,418 +798,127 @@ func (c *Client) getRepositoryInfoWithFilters(repository, tagPattern, platformFi
 		if len(filteredTags) == 0 {
 			return nil, fmt.Errorf("没有匹配的标签")
 		}
+		// 版本约束按版本号从新到旧排列，最新的版本作为主要标签
+		if _, ok := tagPattern.(*Constraint); ok {
+			SortTagsByVersion(filteredTags)
+		}
 	} else {
 		// 没有标签模式，使用所有标签
 		filteredTags = tags
 	}
-	
+
 	// 步骤2: 如果有平台过滤，过滤出支持该平台的标签
-	var platformSupportedTags []string
+	platformSupportedTags := filteredTags
 	if platformFilter != "" {
-		for _, tag := range filteredTags {
-			tagPlatforms := c.GetImagePlatforms(repository, tag)
-			for _, imgPlatform := range tagPlatforms {
-				if strings.Contains(strings.ToLower(imgPlatform), strings.ToLower(platformFilter)) {
-					platformSupportedTags = append(platformSupportedTags, tag)
-					break
-				}
-			}
-		}
+		platformSupportedTags = c.filterTagsByPlatform(ctx, repository, filteredTags, platformFilter)
 		// 如果没有支持指定平台的标签，返回错误
 		if len(platformSupportedTags) == 0 {
 			return nil, fmt.Errorf("没有支持平台 %s 的标签", platformFilter)
 		}
-	} else {
-		// 没有平台过滤，使用过滤后的标签
-		platformSupportedTags = filteredTags
 	}
-	
+
 	// 步骤3: 根据是否有标签模式决定显示策略
 	var matchedTags []string
 	var selectedTag string
 	var platforms []string
-	var totalSize int64
 	var selectedDigest, selectedCreated string
-	
-	if tagPattern != "" {
+
+	if tagPattern != nil {
 		// 指定了标签模式：显示所有匹配的标签
 		matchedTags = platformSupportedTags
 		// 选择第一个标签作为主要标签（用于显示基本信息）
 		selectedTag = platformSupportedTags[0]
 	} else {
 		// 没有标签模式：选择最佳标签
-		selectedTag = platformSupportedTags[0]
-		// 优先选择 latest 标签
-		for _, tag := range platformSupportedTags {
-			if tag == "latest" {
-				selectedTag = tag
-				break
-			}
-		}
-		// 如果没有 latest，比较所有标签的时间戳，选择最新的
-		if selectedTag == platformSupportedTags[0] {
-			var latestTime time.Time
-			var latestTags []string
-			
-			// 遍历所有标签，找到时间戳最新的
-			for _, tag := range platformSupportedTags {
-				manifest, err := c.getImageManifest(repository, tag)
-				if err == nil {
-					// 解析时间戳
-					if manifest.Created != "" {
-						// 尝试解析时间戳
-						if t, err := time.Parse(TimeFormat, manifest.Created); err == nil {
-							if latestTime.IsZero() || t.After(latestTime) {
-								latestTime = t
-								latestTags = []string{tag}
-							} else if t.Equal(latestTime) {
-								// 时间戳相同，添加到候选列表
-								latestTags = append(latestTags, tag)
-							}
-						}
-					}
-				}
-			}
-			
-			// 如果找到了时间戳最新的标签
-			if len(latestTags) > 0 {
-				if len(latestTags) == 1 {
-					// 只有一个最新标签
-					selectedTag = latestTags[0]
-				} else {
-					// 多个标签时间戳相同，优先选择多架构标签
-					var multiArchTag string
-					for _, tag := range latestTags {
-						tagPlatforms := c.GetImagePlatforms(repository, tag)
-						if len(tagPlatforms) > 1 {
-							// 找到多架构标签，优先选择
-							multiArchTag = tag
-							break
-						}
-					}
-					
-					if multiArchTag != "" {
-						selectedTag = multiArchTag
-					} else {
-						// 没有多架构标签，选择第一个
-						selectedTag = latestTags[0]
-					}
-				}
-			}
-		}
+		selectedTag = c.selectBestTag(ctx, repository, platformSupportedTags, platformFilter)
 	}
-	
+
 	// 获取选中标签的平台信息
-	platforms = c.GetImagePlatforms(repository, selectedTag)
-	
+	platforms = c.GetImagePlatforms(ctx, repository, selectedTag)
+
 	// 计算总大小和获取标签详情
-	for i, tag := range platformSupportedTags {
-		if i >= 5 { // 限制检查的标签数量以提高性能
-			break
+	checkedTags := platformSupportedTags
+	if len(checkedTags) > 5 { // 限制检查的标签数量以提高性能
+		checkedTags = checkedTags[:5]
+	}
+	manifests := make([]*Manifest, len(checkedTags))
+	c.runConcurrently(ctx, len(checkedTags), func(i int) {
+		if manifest, err := c.getImageManifest(ctx, repository, checkedTags[i], platformFilter); err == nil {
+			manifests[i] = manifest
 		}
-		manifest, err := c.getImageManifest(repository, tag)
-		if err == nil {
-			totalSize += manifest.Size
-			// 记录选中标签的digest和created
-			if tag == selectedTag {
-				selectedDigest = manifest.Digest
-				selectedCreated = manifest.Created
-			}
+	})
+
+	var totalSize int64
+	for i, manifest := range manifests {
+		if manifest == nil {
+			continue
+		}
+		totalSize += manifest.Size
+		// 记录选中标签的digest和created
+		if checkedTags[i] == selectedTag {
+			selectedDigest = manifest.Digest
+			selectedCreated = manifest.Created
 		}
 	}
-	
+
 	return &SearchResult{
 		Name:        repository,
 		Description: fmt.Sprintf("包含 %d 个标签", len(tags)),
 		Tags:        len(tags),
-		Size:        formatSize(totalSize),
+		Size:        FormatSize(totalSize),
 		Platforms:   platforms,
 		Digest:      selectedDigest,
 		Created:     selectedCreated,
 		LatestTag:   selectedTag,
 		MatchedTags: matchedTags,
+		platform:    platformFilter,
+		sizeBytes:   totalSize,
 	}, nil
 }
 
 // GetImagePlatforms 获取单个镜像的平台信息
-func (c *Client) GetImagePlatforms(repository, tag string) []string {
+func (c *Client) GetImagePlatforms(ctx context.Context, repository, tag string) []string {
 	// 检查认证信息
 	if c.credentials == nil {
 		return []string{"unknown"}
 	}
-	
-	// 解析manifest获取平台信息
-	// 这里需要获取manifest的详细内容来解析平台信息
-	apiURL := fmt.Sprintf("https://%s/v2/%s/manifests/%s", c.registryURL, repository, tag)
-	
-	req, err := http.NewRequest("GET", apiURL, nil)
-	if err != nil {
-		return []string{"unknown"}
-	}
 
-	// 添加认证头
-	auth := base64.StdEncoding.EncodeToString([]byte(c.credentials.Username + ":" + c.credentials.Password))
-	req.Header.Set("Authorization", "Basic "+auth)
-	// 优先请求manifest list（多架构），如果没有则回退到单架构manifest
-	req.Header.Set("Accept", "application/vnd.docker.distribution.manifest.list.v2+json, application/vnd.oci.image.index.v1+json, application/vnd.docker.distribution.manifest.v2+json, application/vnd.oci.image.manifest.v1+json, application/vnd.docker.distribution.manifest.v1+prettyjws")
-	
-	resp, err := c.httpClient.Do(req)
+	// 获取manifest的详细内容来解析平台信息
+	manifest, err := c.getManifest(ctx, repository, tag)
 	if err != nil {
 		return []string{"unknown"}
 	}
-	defer resp.Body.Close()
-	
-	if resp.StatusCode != http.StatusOK {
-		return []string{"unknown"}
-	}
-	
-	// 解析manifest
-	var manifestData map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&manifestData); err != nil {
-		return []string{"unknown"}
-	}
-	
-	// 检查是否是manifest list（多平台）
-	if mediaType, ok := manifestData["mediaType"].(string); ok {
-			// 调试信息（可选，用于开发时查看）
-	// if strings.Contains(repository, "nginx") || strings.Contains(repository, "node") {
-	// 	fmt.Printf("DEBUG: %s:%s mediaType = %s\n", repository, tag, mediaType)
-	// }
-		
-		if mediaType == "application/vnd.docker.distribution.manifest.list.v2+json" || mediaType == "application/vnd.oci.image.index.v1+json" {
-			// 多平台manifest（Docker 或 OCI 格式）
-			if manifests, ok := manifestData["manifests"].([]interface{}); ok {
-				var platforms []string
-				for _, m := range manifests {
-					if manifest, ok := m.(map[string]interface{}); ok {
-						if platform, ok := manifest["platform"].(map[string]interface{}); ok {
-							os, osOk := platform["os"].(string)
-							arch, archOk := platform["architecture"].(string)
-							if osOk && archOk && os != "unknown" && arch != "unknown" {
-								platforms = append(platforms, fmt.Sprintf("%s/%s", os, arch))
-							} else {
-								// 不添加无效平台，直接跳过
-							}
-						} else {
-							// 不添加 unknown/unknown，直接跳过
-						}
-					}
-				}
-				if len(platforms) > 0 {
-					return platforms
-				}
-			}
-		} else if mediaType == "application/vnd.docker.distribution.manifest.v2+json" || mediaType == "application/vnd.oci.image.manifest.v1+json" {
-			// 单平台manifest（Docker 或 OCI 格式），尝试从config中获取平台信息
-			if config, ok := manifestData["config"].(map[string]interface{}); ok {
-				if platform, ok := config["platform"].(map[string]interface{}); ok {
-					
-					if os, ok := platform["os"].(string); ok {
-						if arch, ok := platform["architecture"].(string); ok {
-							platformStr := fmt.Sprintf("%s/%s", os, arch)
-							return []string{platformStr}
-						}
-					}
-				}
-			}
+
+	switch {
+	case manifest.Index != nil:
+		// 多平台manifest（Docker 或 OCI 格式）
+		var platforms []string
+		for _, descriptor := range indexPlatformManifests(manifest.Index) {
+			platforms = append(platforms, descriptor.Platform.String())
 		}
-	}
-	
-	// 尝试从其他可能的字段获取平台信息
-	// 有些镜像可能将平台信息放在不同的位置
-	if config, ok := manifestData["config"].(map[string]interface{}); ok {
-		// 尝试从config的其他字段推断平台
-		if os, ok := config["os"].(string); ok {
-			if arch, ok := config["architecture"].(string); ok {
-				return []string{fmt.Sprintf("%s/%s", os, arch)}
-			}
+		return platforms
+	case manifest.Manifest != nil:
+		// 单平台manifest（Docker 或 OCI 格式），从config descriptor或config blob中获取平台信息
+		if p := manifest.Manifest.Config.Platform; p != nil && p.OS != "" && p.Architecture != "" {
+			return []string{p.String()}
 		}
-	}
-	
-	// 尝试从config blob获取平台信息
-	if config, ok := manifestData["config"].(map[string]interface{}); ok {
-		if digest, ok := config["digest"].(string); ok {
-			// 获取config blob
-			configPlatforms := c.getConfigPlatforms(repository, digest)
-			if len(configPlatforms) > 0 {
-				return configPlatforms
-			}
+		return c.getConfigPlatforms(ctx, repository, manifest.Manifest.Config.Digest)
+	case manifest.V1 != nil:
+		// Docker v1格式：从history中获取平台信息
+		if images := manifest.V1.v1Images(); len(images) > 0 && images[0].OS != "" && images[0].Architecture != "" {
+			return []string{fmt.Sprintf("%s/%s", images[0].OS, images[0].Architecture)}
 		}
 	}
-	
-	// 尝试从layers或其他字段推断平台
-	if layers, ok := manifestData["layers"].([]interface{}); ok && len(layers) > 0 {
-		// 如果无法确定具体平台，但知道有layers，可能是linux平台
-		// 这里可以根据实际情况调整
-		// 注意：不要在这里硬编码平台，让调用者决定如何处理
-		return []string{}
-	}
-	
+
 	// 如果无法获取平台信息，返回空列表，让调用者决定如何处理
 	return []string{}
 }
 
-// matchesTagPattern 检查标签是否匹配模式
-func matchesTagPattern(tag, pattern string) bool {
-	if pattern == "" {
-		return false
-	}
-	
-	// 处理通配符模式
-	if strings.Contains(pattern, "*") {
-		// 将通配符模式转换为正则表达式
-		// *22-alpine -> .*22-alpine$ (以22-alpine结尾)
-		// 22*alpine -> ^22.*alpine$ (以22开头，以alpine结尾)
-		// 22-alpine* -> ^22-alpine.* (以22-alpine开头)
-		// *2* -> .*2.* (包含2)
-		
-		var regexPattern string
-		if strings.HasPrefix(pattern, "*") && strings.HasSuffix(pattern, "*") {
-			// *xxx* -> .*xxx.* (包含xxx)
-			if len(pattern) > 2 {
-				middle := pattern[1 : len(pattern)-1]
-				regexPattern = ".*" + regexp.QuoteMeta(middle) + ".*"
-			} else {
-				// 如果模式就是 "*"，匹配所有内容
-				regexPattern = ".*"
-			}
-		} else if strings.HasPrefix(pattern, "*") {
-			// *xxx -> .*xxx$ (以xxx结尾)
-			suffix := pattern[1:]
-			regexPattern = ".*" + regexp.QuoteMeta(suffix) + "$"
-		} else if strings.HasSuffix(pattern, "*") {
-			// xxx* -> ^xxx.* (以xxx开头)
-			prefix := pattern[:len(pattern)-1]
-			regexPattern = "^" + regexp.QuoteMeta(prefix) + ".*"
-		} else {
-			// xxx*yyy -> ^xxx.*yyy$ (以xxx开头，以yyy结尾)
-			parts := strings.SplitN(pattern, "*", 2)
-			if len(parts) == 2 {
-				prefix := parts[0]
-				suffix := parts[1]
-				regexPattern = "^" + regexp.QuoteMeta(prefix) + ".*" + regexp.QuoteMeta(suffix) + "$"
-			} else {
-				// 如果分割失败，回退到简单替换
-				regexPattern = strings.ReplaceAll(pattern, "*", ".*")
-			}
-		}
-		
-		// 调试信息（可选）
-		// if strings.Contains(tag, "22") || strings.Contains(tag, "2.0") {
-		// 	fmt.Printf("DEBUG: tag '%s' pattern '%s' -> regex '%s'\n", tag, pattern, regexPattern)
-		// }
-		
-		// 编译正则表达式
-		re, err := regexp.Compile(regexPattern)
-		if err != nil {
-			// 如果正则表达式编译失败，使用简单的字符串包含
-			return strings.Contains(strings.ToLower(tag), strings.ToLower(pattern))
-		}
-		
-		// 使用正则表达式匹配
-		return re.MatchString(tag)
-	}
-	
-	// 没有通配符，使用精确匹配
-	return tag == pattern
-}
-
 // getConfigPlatforms 从config blob获取平台信息
-func (c *Client) getConfigPlatforms(repository, digest string) []string {
-	// 构建API URL获取config blob
-	apiURL := fmt.Sprintf("https://%s/v2/%s/blobs/%s", c.registryURL, repository, digest)
-	
-	req, err := http.NewRequest("GET", apiURL, nil)
-	if err != nil {
-		return []string{}
-	}
-	
-	// 添加认证头
-	auth := base64.StdEncoding.EncodeToString([]byte(c.credentials.Username + ":" + c.credentials.Password))
-	req.Header.Set("Authorization", "Basic "+auth)
-	
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
+func (c *Client) getConfigPlatforms(ctx context.Context, repository, digest string) []string {
+	config, err := c.getImageConfig(ctx, repository, digest)
+	if err != nil || config.OS == "" || config.Architecture == "" {
 		return []string{}
 	}
-	defer resp.Body.Close()
-	
-	if resp.StatusCode != http.StatusOK {
-		return []string{}
-	}
-	
-	// 解析config blob
-	var configData map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&configData); err != nil {
-		return []string{}
-	}
-	
-	// 从config中获取平台信息
-	if os, ok := configData["os"].(string); ok {
-		if arch, ok := configData["architecture"].(string); ok {
-			return []string{fmt.Sprintf("%s/%s", os, arch)}
-		}
-	}
-	
-	return []string{}
+	return []string{config.Platform().String()}
 }
-
-// getMapKeys 获取map的所有键
-func getMapKeys(m map[string]interface{}) []string {
-	var keys []string
-	for k := range m {
-		keys = append(keys, k)
-	}
-	return keys
-}
-
-// getRepositoryPlatforms 获取仓库支持的平台
-func (c *Client) getRepositoryPlatforms(repository string, tags []string) []string {
-	var platforms []string
-	platformSet := make(map[string]bool)
-	
-	// 检查前几个标签的平台信息
-	for i, tag := range tags {
-		if i >= 5 { // 限制检查的标签数量
-			break
-		}
-		
-		_, err := c.getImageManifest(repository, tag)
-		if err != nil {
-			continue
-		}
-		
-		// 这里可以解析manifest获取平台信息
-		// 简化处理，返回通用平台
-		platformSet["linux/amd64"] = true
-		platformSet["linux/arm64"] = true
-	}
-	
-	// 转换为切片
-	for platform := range platformSet {
-		platforms = append(platforms, platform)
-	}
-	
-	if len(platforms) == 0 {
-		platforms = []string{"unknown"}
-	}
-	
-	return platforms
-}
-
-// filterImagesByPlatform 根据平台过滤镜像
-func (c *Client) filterImagesByPlatform(images []Image, platform string) []Image {
-	var filtered []Image
-	
-	for _, img := range images {
-		// 检查镜像是否支持指定平台
-		for _, imgPlatform := range img.Platforms {
-			if strings.Contains(strings.ToLower(imgPlatform), strings.ToLower(platform)) {
-				filtered = append(filtered, img)
-				break
-			}
-		}
-	}
-	
-	return filtered
-}
\ No newline at end of file
@@ -0,0 +1,85 @@
+package registry
+
+import (
+	"context"
+	"sync"
+)
+
+// DefaultConcurrency 默认的并发请求数量
+const DefaultConcurrency = 4
+
+// SetConcurrency 设置获取元数据时的最大并发数量
+func (c *Client) SetConcurrency(n int) {
+	if n < 1 {
+		n = 1
+	}
+	c.concurrency = n
+	c.requestSlots = make(chan struct{}, n)
+}
+
+// runConcurrently 以有限的worker数量执行 fn(0)...fn(n-1)，全部完成后返回
+// 调用方按下标写入结果切片即可保证输出顺序与输入一致；ctx被取消后不再分派新的任务，
+// 调用方需要检查 ctx.Err() 以区分结果是否完整
+func (c *Client) runConcurrently(ctx context.Context, n int, fn func(i int)) {
+	workers := c.concurrency
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > n {
+		workers = n
+	}
+
+	if workers <= 1 {
+		for i := 0; i < n && ctx.Err() == nil; i++ {
+			fn(i)
+		}
+		return
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				fn(i)
+			}
+		}()
+	}
+
+dispatch:
+	for i := 0; i < n; i++ {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// acquireRequestSlot 占用一个请求槽位，限制同时进行的HTTP请求数量
+// 嵌套的worker（仓库 -> 标签）共享同一组槽位，因此总并发不会超过设置值
+// 等待槽位期间ctx被取消时返回ctx的错误
+func (c *Client) acquireRequestSlot(ctx context.Context) (func(), error) {
+	if c.requestSlots == nil {
+		return func() {}, nil
+	}
+	select {
+	case c.requestSlots <- struct{}{}:
+		return func() { <-c.requestSlots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// GetTagsPlatforms 并发获取多个标签的平台信息，返回结果与 tags 一一对应
+func (c *Client) GetTagsPlatforms(ctx context.Context, repository string, tags []string) [][]string {
+	platforms := make([][]string, len(tags))
+	c.runConcurrently(ctx, len(tags), func(i int) {
+		platforms[i] = c.GetImagePlatforms(ctx, repository, tags[i])
+	})
+	return platforms
+}
@@ -0,0 +1,104 @@
+package registry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// errCredentialsNotFound 凭证助手中没有该registry的凭证
+var errCredentialsNotFound = errors.New("凭证助手中未找到认证信息")
+
+// identityTokenUsername 凭证助手中表示Secret为identity token的用户名
+const identityTokenUsername = "<token>"
+
+// credentialHelperOutput 凭证助手 get 命令的输出
+type credentialHelperOutput struct {
+	ServerURL string `json:"ServerURL"`
+	Username  string `json:"Username"`
+	Secret    string `json:"Secret"`
+}
+
+// credentialHelperProgram 返回凭证助手对应的可执行文件名，如 pass → docker-credential-pass
+func credentialHelperProgram(helper string) string {
+	return "docker-credential-" + helper
+}
+
+// helperGet 使用凭证助手的 get 命令获取凭证
+func helperGet(ctx context.Context, helper, serverURL string) (*Credentials, error) {
+	output, err := runCredentialHelper(ctx, helper, "get", serverURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var creds credentialHelperOutput
+	if err := json.Unmarshal(output, &creds); err != nil {
+		return nil, fmt.Errorf("解析凭证助手输出失败: %v", err)
+	}
+	if creds.Username == "" || creds.Secret == "" {
+		return nil, errCredentialsNotFound
+	}
+	if creds.Username == identityTokenUsername {
+		return &Credentials{IdentityToken: creds.Secret}, nil
+	}
+
+	return &Credentials{
+		Username: creds.Username,
+		Password: creds.Secret,
+	}, nil
+}
+
+// helperStore 使用凭证助手的 store 命令保存凭证，identity token 以用户名 <token> 保存
+func helperStore(ctx context.Context, helper, serverURL string, creds *Credentials) error {
+	username, secret := creds.Username, creds.Password
+	if creds.IdentityToken != "" {
+		username, secret = identityTokenUsername, creds.IdentityToken
+	}
+	data, err := json.Marshal(credentialHelperOutput{
+		ServerURL: serverURL,
+		Username:  username,
+		Secret:    secret,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = runCredentialHelper(ctx, helper, "store", string(data))
+	return err
+}
+
+// helperErase 使用凭证助手的 erase 命令删除凭证
+func helperErase(ctx context.Context, helper, serverURL string) error {
+	_, err := runCredentialHelper(ctx, helper, "erase", serverURL)
+	return err
+}
+
+// runCredentialHelper 执行凭证助手命令，input 通过标准输入传入
+// 凭证助手约定在出错时把错误信息输出到标准输出
+func runCredentialHelper(ctx context.Context, helper, action, input string) ([]byte, error) {
+	program := credentialHelperProgram(helper)
+	cmd := exec.CommandContext(ctx, program, action)
+	cmd.Stdin = strings.NewReader(input)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		message := strings.TrimSpace(stdout.String())
+		if message == "" {
+			message = strings.TrimSpace(stderr.String())
+		}
+		if strings.Contains(message, "credentials not found") {
+			return nil, errCredentialsNotFound
+		}
+		if message != "" {
+			return nil, fmt.Errorf("%s %s 失败: %s", program, action, message)
+		}
+		return nil, fmt.Errorf("%s %s 失败: %v", program, action, err)
+	}
+	return stdout.Bytes(), nil
+}
@@ -0,0 +1,259 @@
+package registry
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// ErrNoCredentialStore 没有可用的本地加密凭证存储
+var ErrNoCredentialStore = errors.New("没有可用的加密凭证存储，请启用系统密钥环或设置 DOCKER_GENEE_PASSPHRASE")
+
+// credentialStore 本地凭证存储，按registry保存用户名和密码
+type credentialStore interface {
+	// Name 返回存储的名称，用于提示信息
+	Name() string
+	// Load 读取registry的凭证，不存在时返回 errCredentialsNotFound
+	Load(ctx context.Context, registry string) (*Credentials, error)
+	// Save 保存registry的凭证
+	Save(ctx context.Context, registry string, creds *Credentials) error
+	// Delete 删除registry的凭证，返回是否有凭证被删除
+	Delete(ctx context.Context, registry string) (bool, error)
+}
+
+// credentialStore 按 DOCKER_GENEE_CREDENTIAL_STORE 环境变量选择本地凭证存储：
+// keyring 使用系统密钥环，file 使用口令加密的文件，默认优先使用系统密钥环
+func (c *Client) credentialStore() (credentialStore, error) {
+	keyring := newKeyringStore()
+	file := newEncryptedFileStore(filepath.Join(c.configDir, "credentials.enc"), os.Getenv("DOCKER_GENEE_PASSPHRASE"))
+
+	switch name := os.Getenv("DOCKER_GENEE_CREDENTIAL_STORE"); name {
+	case "keyring":
+		if !keyring.available() {
+			return nil, fmt.Errorf("系统密钥环不可用，需要 secret-tool 和 D-Bus 会话")
+		}
+		return keyring, nil
+	case "file":
+		if !file.available() {
+			return nil, fmt.Errorf("使用加密文件存储凭证时需要设置 DOCKER_GENEE_PASSPHRASE")
+		}
+		return file, nil
+	case "", "auto":
+		if keyring.available() {
+			return keyring, nil
+		}
+		if file.available() {
+			return file, nil
+		}
+		return nil, ErrNoCredentialStore
+	default:
+		return nil, fmt.Errorf("不支持的凭证存储: %s", name)
+	}
+}
+
+// legacyCredentialsRegistry 旧版本只能登录默认镜像源，明文凭证文件中没有记录registry，
+// 其中的凭证都属于该镜像源
+const legacyCredentialsRegistry = "docker.genee.cn"
+
+// MigrateLocalCredentials 将旧版本保存的明文凭证文件导入加密存储并删除明文文件
+// 返回导入到的存储名称，没有需要迁移的文件时返回空字符串
+func (c *Client) MigrateLocalCredentials(ctx context.Context) (string, error) {
+	data, err := os.ReadFile(c.localCredentialsPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+
+	store, err := c.credentialStore()
+	if err != nil {
+		return "", err
+	}
+
+	var creds Credentials
+	if err := json.Unmarshal(data, &creds); err != nil {
+		return "", fmt.Errorf("解析明文凭证文件失败: %v", err)
+	}
+	if creds.Username != "" && creds.Password != "" {
+		err := store.Save(ctx, legacyCredentialsRegistry, &Credentials{Username: creds.Username, Password: creds.Password})
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if err := os.Remove(c.localCredentialsPath()); err != nil {
+		return "", err
+	}
+	return store.Name(), nil
+}
+
+// encryptedFileStore 使用口令加密的凭证文件
+// 口令经scrypt派生出AES-256密钥，文件内容使用AES-GCM加密
+type encryptedFileStore struct {
+	path       string
+	passphrase string
+}
+
+// encryptedFile 加密凭证文件的格式
+type encryptedFile struct {
+	Version    int    `json:"version"`
+	KDF        string `json:"kdf"`
+	N          int    `json:"n"`
+	R          int    `json:"r"`
+	P          int    `json:"p"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// scrypt 参数
+const (
+	scryptN      = 1 << 15
+	scryptR      = 8
+	scryptP      = 1
+	scryptKeyLen = 32
+)
+
+func newEncryptedFileStore(path, passphrase string) *encryptedFileStore {
+	return &encryptedFileStore{path: path, passphrase: passphrase}
+}
+
+func (s *encryptedFileStore) available() bool {
+	return s.passphrase != ""
+}
+
+func (s *encryptedFileStore) Name() string {
+	return s.path
+}
+
+func (s *encryptedFileStore) Load(ctx context.Context, registry string) (*Credentials, error) {
+	entries, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	creds, ok := entries[registry]
+	if !ok {
+		return nil, errCredentialsNotFound
+	}
+	return creds, nil
+}
+
+func (s *encryptedFileStore) Save(ctx context.Context, registry string, creds *Credentials) error {
+	entries, err := s.read()
+	if err != nil {
+		return err
+	}
+	entries[registry] = creds
+	return s.write(entries)
+}
+
+func (s *encryptedFileStore) Delete(ctx context.Context, registry string) (bool, error) {
+	entries, err := s.read()
+	if err != nil {
+		return false, err
+	}
+	if _, ok := entries[registry]; !ok {
+		return false, nil
+	}
+	delete(entries, registry)
+	if len(entries) == 0 {
+		return removeFile(s.path)
+	}
+	return true, s.write(entries)
+}
+
+// read 解密凭证文件，文件不存在时返回空的凭证表
+func (s *encryptedFileStore) read() (map[string]*Credentials, error) {
+	entries := map[string]*Credentials{}
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return entries, nil
+		}
+		return nil, err
+	}
+
+	var file encryptedFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("解析加密凭证文件失败: %v", err)
+	}
+	if file.Version != 1 || file.KDF != "scrypt" {
+		return nil, fmt.Errorf("不支持的加密凭证文件格式")
+	}
+
+	gcm, err := s.cipher(file.Salt, file.N, file.R, file.P)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := gcm.Open(nil, file.Nonce, file.Ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("解密凭证文件失败，口令错误或文件已损坏")
+	}
+
+	if err := json.Unmarshal(plaintext, &entries); err != nil {
+		return nil, fmt.Errorf("解析加密凭证文件失败: %v", err)
+	}
+	return entries, nil
+}
+
+// write 使用新的盐和随机数加密凭证表并写入文件
+func (s *encryptedFileStore) write(entries map[string]*Credentials) error {
+	plaintext, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+
+	file := encryptedFile{
+		Version: 1,
+		KDF:     "scrypt",
+		N:       scryptN,
+		R:       scryptR,
+		P:       scryptP,
+		Salt:    make([]byte, 16),
+	}
+	if _, err := rand.Read(file.Salt); err != nil {
+		return err
+	}
+
+	gcm, err := s.cipher(file.Salt, file.N, file.R, file.P)
+	if err != nil {
+		return err
+	}
+	file.Nonce = make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(file.Nonce); err != nil {
+		return err
+	}
+	file.Ciphertext = gcm.Seal(nil, file.Nonce, plaintext, nil)
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, data, 0600)
+}
+
+// cipher 由口令派生密钥并创建AES-GCM
+func (s *encryptedFileStore) cipher(salt []byte, n, r, p int) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(s.passphrase), salt, n, r, p, scryptKeyLen)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,195 @@
+package registry
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// dockerConfigFileName Docker CLI配置文件的文件名
+const dockerConfigFileName = "config.json"
+
+// DefaultDockerConfigDir 返回Docker CLI的配置目录：
+// 优先使用 DOCKER_CONFIG 环境变量，否则为 ~/.docker
+func DefaultDockerConfigDir() string {
+	if dir := os.Getenv("DOCKER_CONFIG"); dir != "" {
+		return dir
+	}
+	return filepath.Join(homeDir(), ".docker")
+}
+
+// DefaultConfigDir 返回插件自己的配置目录：
+// 优先使用 DOCKER_GENEE_CONFIG 环境变量，否则为 ~/.docker-genee
+func DefaultConfigDir() string {
+	if dir := os.Getenv("DOCKER_GENEE_CONFIG"); dir != "" {
+		return dir
+	}
+	return filepath.Join(homeDir(), ".docker-genee")
+}
+
+// homeDir 返回用户主目录，无法获取时使用 HOME 环境变量
+func homeDir() string {
+	if home, err := os.UserHomeDir(); err == nil {
+		return home
+	}
+	return os.Getenv("HOME")
+}
+
+// dockerConfig 表示Docker CLI的配置文件（config.json）
+// 只解析与认证相关的字段，保存时其他字段保持不变
+type dockerConfig struct {
+	path string
+	raw  map[string]json.RawMessage
+
+	Auths       map[string]dockerAuthConfig
+	CredsStore  string
+	CredHelpers map[string]string
+}
+
+// dockerAuthConfig 表示config.json中 auths 下的一个条目
+type dockerAuthConfig struct {
+	Auth     string `json:"auth,omitempty"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	Email    string `json:"email,omitempty"`
+	// IdentityToken OAuth2 refresh token，存在时 auth 中的密码为空
+	IdentityToken string `json:"identitytoken,omitempty"`
+}
+
+// loadDockerConfig 读取Docker配置文件，文件不存在时返回空配置
+func loadDockerConfig(path string) (*dockerConfig, error) {
+	config := &dockerConfig{
+		path:        path,
+		raw:         map[string]json.RawMessage{},
+		Auths:       map[string]dockerAuthConfig{},
+		CredHelpers: map[string]string{},
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return config, nil
+		}
+		return nil, err
+	}
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return config, nil
+	}
+
+	if err := json.Unmarshal(data, &config.raw); err != nil {
+		return nil, fmt.Errorf("解析Docker配置文件 %s 失败: %v", path, err)
+	}
+	fields := []struct {
+		key   string
+		value interface{}
+	}{
+		{"auths", &config.Auths},
+		{"credsStore", &config.CredsStore},
+		{"credHelpers", &config.CredHelpers},
+	}
+	for _, field := range fields {
+		raw, ok := config.raw[field.key]
+		if !ok {
+			continue
+		}
+		if err := json.Unmarshal(raw, field.value); err != nil {
+			return nil, fmt.Errorf("解析Docker配置文件 %s 失败: %v", path, err)
+		}
+	}
+	if config.Auths == nil {
+		config.Auths = map[string]dockerAuthConfig{}
+	}
+	return config, nil
+}
+
+// credentialHelper 返回registry使用的凭证助手名称，与Docker CLI一致：
+// 优先使用 credHelpers 中为该registry配置的助手，否则使用 credsStore，都未配置时返回空字符串
+func (config *dockerConfig) credentialHelper(registry string) string {
+	if helper, ok := config.CredHelpers[registry]; ok && helper != "" {
+		return helper
+	}
+	return config.CredsStore
+}
+
+// authConfig 查找registry对应的 auths 条目
+// 先精确匹配，再按主机名匹配（兼容 https://registry/v1/ 形式的键）
+func (config *dockerConfig) authConfig(registry string) (dockerAuthConfig, bool) {
+	if auth, ok := config.Auths[registry]; ok {
+		return auth, true
+	}
+	for key, auth := range config.Auths {
+		if convertToHostname(key) == registry {
+			return auth, true
+		}
+	}
+	return dockerAuthConfig{}, false
+}
+
+// credentials 将 auths 条目解码为认证信息
+func (auth dockerAuthConfig) credentials() (*Credentials, error) {
+	username, password := auth.Username, auth.Password
+	if auth.Auth != "" {
+		decoded, err := base64.StdEncoding.DecodeString(auth.Auth)
+		if err != nil {
+			return nil, err
+		}
+		parts := strings.SplitN(string(decoded), ":", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("无效的认证信息")
+		}
+		username, password = parts[0], parts[1]
+	}
+	if auth.IdentityToken != "" {
+		return &Credentials{Username: username, IdentityToken: auth.IdentityToken}, nil
+	}
+	if username == "" || password == "" {
+		return nil, fmt.Errorf("未找到对应的认证信息")
+	}
+	return &Credentials{Username: username, Password: password}, nil
+}
+
+// setAuth 设置registry的 auths 条目
+func (config *dockerConfig) setAuth(registry string, auth dockerAuthConfig) {
+	config.Auths[registry] = auth
+}
+
+// removeAuth 删除registry的所有 auths 条目，返回是否有条目被删除
+func (config *dockerConfig) removeAuth(registry string) bool {
+	removed := false
+	for key := range config.Auths {
+		if key == registry || convertToHostname(key) == registry {
+			delete(config.Auths, key)
+			removed = true
+		}
+	}
+	return removed
+}
+
+// save 将配置写回文件，未解析的字段原样保留
+func (config *dockerConfig) save() error {
+	auths, err := json.Marshal(config.Auths)
+	if err != nil {
+		return err
+	}
+	config.raw["auths"] = auths
+
+	data, err := json.MarshalIndent(config.raw, "", "\t")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(config.path), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(config.path, data, 0600)
+}
+
+// convertToHostname 去掉地址中的协议和路径，只保留主机名（含端口）
+func convertToHostname(address string) string {
+	address = strings.TrimPrefix(address, "https://")
+	address = strings.TrimPrefix(address, "http://")
+	host, _, _ := strings.Cut(address, "/")
+	return host
+}
@@ -0,0 +1,169 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// 访问registry使用的协议
+const (
+	SchemeHTTPS = "https"
+	SchemeHTTP  = "http"
+)
+
+// splitRegistryURL 拆分 --registry 中可选的协议，如 http://localhost:5000 → http, localhost:5000
+func splitRegistryURL(registryURL string) (string, string) {
+	scheme := ""
+	if s, rest, ok := strings.Cut(registryURL, "://"); ok {
+		scheme, registryURL = strings.ToLower(s), rest
+	}
+	return scheme, strings.TrimSuffix(registryURL, "/")
+}
+
+// validateScheme 检查协议是否受支持，空字符串表示默认的https
+func validateScheme(scheme string) error {
+	switch scheme {
+	case "", SchemeHTTPS, SchemeHTTP:
+		return nil
+	}
+	return fmt.Errorf("不支持的协议: %s", scheme)
+}
+
+// SetScheme 设置访问registry使用的协议（https 或 http），为空时默认使用https
+func (c *Client) SetScheme(scheme string) error {
+	if err := validateScheme(scheme); err != nil {
+		return err
+	}
+	c.schemeMu.Lock()
+	c.scheme = scheme
+	c.schemeMu.Unlock()
+	return nil
+}
+
+// SetInsecure 设置是否将registry视为不安全的registry：
+// 跳过TLS证书校验，并在registry不支持HTTPS时回退到HTTP
+func (c *Client) SetInsecure(insecure bool) {
+	c.insecure = insecure
+	c.configureTransport()
+}
+
+// Insecure 返回是否将registry视为不安全的registry
+func (c *Client) Insecure() bool {
+	return c.insecure
+}
+
+// endpoint 返回registry API的完整地址，path 以 /v2/ 开头
+func (c *Client) endpoint(ctx context.Context, path string) string {
+	return fmt.Sprintf("%s://%s%s", c.resolveScheme(ctx), c.registryURL, path)
+}
+
+// resolveScheme 返回访问registry使用的协议
+// 未指定协议时使用https；不安全的registry在HTTPS不可用时回退到http，探测结果只计算一次
+func (c *Client) resolveScheme(ctx context.Context) string {
+	c.schemeMu.Lock()
+	defer c.schemeMu.Unlock()
+
+	if c.scheme != "" {
+		return c.scheme
+	}
+	if !c.insecure {
+		return SchemeHTTPS
+	}
+
+	c.scheme = SchemeHTTPS
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s://%s/v2/", SchemeHTTPS, c.registryURL), nil)
+	if err == nil {
+		var resp *http.Response
+		if resp, err = c.httpClient.Do(req); err == nil {
+			resp.Body.Close()
+		}
+	}
+	if err != nil && ctx.Err() != nil {
+		// 探测被取消时不记录结果，后续请求会返回取消错误
+		c.scheme = ""
+		return SchemeHTTPS
+	}
+	if err != nil {
+		// 与Docker守护进程一样，HTTPS请求失败时回退到HTTP
+		c.scheme = SchemeHTTP
+	}
+	return c.scheme
+}
+
+// daemonConfigPaths Docker守护进程配置文件可能的位置
+func daemonConfigPaths() []string {
+	paths := []string{"/etc/docker/daemon.json"}
+	// rootless模式和Docker Desktop
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		paths = append(paths, filepath.Join(dir, "docker", "daemon.json"))
+	} else {
+		paths = append(paths, filepath.Join(homeDir(), ".config", "docker", "daemon.json"))
+	}
+	return append(paths, filepath.Join(homeDir(), ".docker", "daemon.json"))
+}
+
+// IsInsecureRegistry 按Docker守护进程的规则判断registry是否为不安全的registry：
+// 回环地址总是视为不安全的registry，其余的需要在 daemon.json 的 insecure-registries 中列出（主机名或CIDR）
+func IsInsecureRegistry(registryURL string) bool {
+	_, host := splitRegistryURL(registryURL)
+
+	hostname := host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		hostname = h
+	}
+	if hostname == "localhost" {
+		return true
+	}
+	if ip := net.ParseIP(hostname); ip != nil && ip.IsLoopback() {
+		return true
+	}
+
+	var cidrs []*net.IPNet
+	for _, path := range daemonConfigPaths() {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var config struct {
+			InsecureRegistries []string `json:"insecure-registries"`
+		}
+		if err := json.Unmarshal(data, &config); err != nil {
+			continue
+		}
+		for _, entry := range config.InsecureRegistries {
+			_, entry = splitRegistryURL(entry)
+			if _, network, err := net.ParseCIDR(entry); err == nil {
+				cidrs = append(cidrs, network)
+			} else if entry == host {
+				return true
+			}
+		}
+	}
+	if len(cidrs) == 0 {
+		return false
+	}
+
+	// CIDR按registry解析出的IP地址匹配
+	ips := []net.IP{net.ParseIP(hostname)}
+	if ips[0] == nil {
+		addrs, err := net.LookupIP(hostname)
+		if err != nil {
+			return false
+		}
+		ips = addrs
+	}
+	for _, ip := range ips {
+		for _, network := range cidrs {
+			if network.Contains(ip) {
+				return true
+			}
+		}
+	}
+	return false
+}
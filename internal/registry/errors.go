@@ -0,0 +1,139 @@
+package registry
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// ErrorCode registry错误码，见distribution规范的错误码定义
+type ErrorCode string
+
+// registry返回的错误码
+const (
+	ErrorCodeUnauthorized        ErrorCode = "UNAUTHORIZED"
+	ErrorCodeDenied              ErrorCode = "DENIED"
+	ErrorCodeUnsupported         ErrorCode = "UNSUPPORTED"
+	ErrorCodeTooManyRequests     ErrorCode = "TOOMANYREQUESTS"
+	ErrorCodeNameInvalid         ErrorCode = "NAME_INVALID"
+	ErrorCodeNameUnknown         ErrorCode = "NAME_UNKNOWN"
+	ErrorCodeTagInvalid          ErrorCode = "TAG_INVALID"
+	ErrorCodeManifestInvalid     ErrorCode = "MANIFEST_INVALID"
+	ErrorCodeManifestUnknown     ErrorCode = "MANIFEST_UNKNOWN"
+	ErrorCodeManifestBlobUnknown ErrorCode = "MANIFEST_BLOB_UNKNOWN"
+	ErrorCodeBlobUnknown         ErrorCode = "BLOB_UNKNOWN"
+	ErrorCodeDigestInvalid       ErrorCode = "DIGEST_INVALID"
+	ErrorCodePaginationInvalid   ErrorCode = "PAGINATION_NUMBER_INVALID"
+)
+
+// errorCodeDescriptions 错误码的中文说明
+var errorCodeDescriptions = map[ErrorCode]string{
+	ErrorCodeUnauthorized:        "未认证",
+	ErrorCodeDenied:              "没有访问权限",
+	ErrorCodeUnsupported:         "镜像源不支持该操作",
+	ErrorCodeTooManyRequests:     "请求过于频繁",
+	ErrorCodeNameInvalid:         "无效的仓库名称",
+	ErrorCodeNameUnknown:         "仓库不存在",
+	ErrorCodeTagInvalid:          "无效的标签",
+	ErrorCodeManifestInvalid:     "无效的清单",
+	ErrorCodeManifestUnknown:     "清单不存在",
+	ErrorCodeManifestBlobUnknown: "清单引用的blob不存在",
+	ErrorCodeBlobUnknown:         "blob不存在",
+	ErrorCodeDigestInvalid:       "摘要与内容不匹配",
+	ErrorCodePaginationInvalid:   "无效的分页参数",
+}
+
+// maxErrorBodySize 读取错误响应体的最大长度
+const maxErrorBodySize = 64 << 10
+
+// RegistryError 表示registry错误响应中的一个错误
+type RegistryError struct {
+	Code    ErrorCode       `json:"code"`
+	Message string          `json:"message"`
+	Detail  json.RawMessage `json:"detail,omitempty"`
+}
+
+// Error 返回带中文说明的错误信息
+func (e RegistryError) Error() string {
+	description, ok := errorCodeDescriptions[e.Code]
+	switch {
+	case !ok && e.Message == "":
+		return string(e.Code)
+	case !ok:
+		return fmt.Sprintf("%s: %s", e.Code, e.Message)
+	case e.Message == "":
+		return fmt.Sprintf("%s (%s)", description, e.Code)
+	}
+	return fmt.Sprintf("%s (%s): %s", description, e.Code, e.Message)
+}
+
+// ResponseError 表示registry或令牌服务返回的非成功响应
+type ResponseError struct {
+	StatusCode int
+	Method     string
+	URL        string
+	// Errors 从响应体 {"errors":[...]} 中解析出的错误，响应体不是该格式时为空
+	Errors []RegistryError
+}
+
+// Error 返回错误信息，优先使用响应体中的错误
+func (e *ResponseError) Error() string {
+	if len(e.Errors) == 0 {
+		return fmt.Sprintf("状态码: %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	messages := make([]string, len(e.Errors))
+	for i, registryErr := range e.Errors {
+		messages[i] = registryErr.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Code 返回错误码：响应体中的第一个错误码，没有时根据状态码推断
+func (e *ResponseError) Code() ErrorCode {
+	if len(e.Errors) > 0 {
+		return e.Errors[0].Code
+	}
+	switch e.StatusCode {
+	case http.StatusUnauthorized:
+		return ErrorCodeUnauthorized
+	case http.StatusForbidden:
+		return ErrorCodeDenied
+	case http.StatusTooManyRequests:
+		return ErrorCodeTooManyRequests
+	}
+	return ""
+}
+
+// newResponseError 读取响应体并解析registry返回的错误
+func newResponseError(resp *http.Response) *ResponseError {
+	responseErr := &ResponseError{StatusCode: resp.StatusCode}
+	if resp.Request != nil {
+		responseErr.Method = resp.Request.Method
+		responseErr.URL = resp.Request.URL.String()
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+	if err != nil || len(body) == 0 {
+		return responseErr
+	}
+
+	var errorsBody struct {
+		Errors []RegistryError `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &errorsBody); err == nil {
+		responseErr.Errors = errorsBody.Errors
+	}
+	return responseErr
+}
+
+// ErrorCodeOf 返回错误链中registry错误的错误码，不是registry错误时返回空字符串
+func ErrorCodeOf(err error) ErrorCode {
+	var responseErr *ResponseError
+	if errors.As(err, &responseErr) {
+		return responseErr.Code()
+	}
+	return ""
+}
@@ -0,0 +1,181 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// manifestAcceptHeader 请求manifest时支持的所有格式，优先多架构格式
+var manifestAcceptHeader = strings.Join([]string{
+	MediaTypeDockerManifestList,
+	MediaTypeOCIIndex,
+	MediaTypeDockerSchema2,
+	MediaTypeOCIManifest,
+	MediaTypeDockerSchema1Signed,
+}, ", ")
+
+// rawManifest 表示从registry或缓存获取的原始manifest
+type rawManifest struct {
+	Digest    string
+	MediaType string
+	Body      []byte
+}
+
+// fetchManifest 获取manifest，reference 可以是标签或digest
+// 按digest获取的内容直接使用缓存；按标签获取时先用HEAD请求解析出当前digest，再查询缓存，
+// 缓存中没有该标签的记录时直接GET，避免对同一个manifest多发一次请求
+func (c *Client) fetchManifest(ctx context.Context, repository, reference string) (*rawManifest, error) {
+	apiURL := c.endpoint(ctx, fmt.Sprintf("/v2/%s/manifests/%s", repository, reference))
+
+	if c.cache != nil {
+		digest := reference
+		mediaType := ""
+		if !isDigest(reference) {
+			// 标签可能被重新指向，缓存中记录过该标签时通过HEAD请求重新验证
+			digest = ""
+			if c.cache.TagDigest(apiURL) != "" {
+				digest, mediaType = c.headManifest(ctx, apiURL)
+			}
+		}
+		if digest != "" {
+			if body, ok := c.cache.Get(digest); ok {
+				if mediaType == "" {
+					mediaType = detectManifestMediaType(body)
+				}
+				return &rawManifest{Digest: digest, MediaType: mediaType, Body: body}, nil
+			}
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", manifestAcceptHeader)
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("获取清单失败: %w", newResponseError(resp))
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	digest := resp.Header.Get("Docker-Content-Digest")
+	if digest == "" && isDigest(reference) {
+		digest = reference
+	}
+
+	mediaType := resp.Header.Get("Content-Type")
+	if mediaType == "" || mediaType == "application/json" {
+		mediaType = detectManifestMediaType(body)
+	}
+
+	if c.cache != nil && digest != "" {
+		// 写入失败不影响结果（例如schema1签名manifest的摘要与内容不一致）
+		if c.cache.Put(digest, body) == nil && !isDigest(reference) {
+			c.cache.SetTagDigest(apiURL, digest)
+		}
+	}
+
+	return &rawManifest{Digest: digest, MediaType: mediaType, Body: body}, nil
+}
+
+// headManifest 使用HEAD请求获取标签当前指向的digest和媒体类型
+func (c *Client) headManifest(ctx context.Context, apiURL string) (string, string) {
+	req, err := http.NewRequestWithContext(ctx, "HEAD", apiURL, nil)
+	if err != nil {
+		return "", ""
+	}
+	req.Header.Set("Accept", manifestAcceptHeader)
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return "", ""
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", ""
+	}
+	return resp.Header.Get("Docker-Content-Digest"), resp.Header.Get("Content-Type")
+}
+
+// fetchBlob 获取blob内容（如config），blob以digest寻址，优先使用缓存
+func (c *Client) fetchBlob(ctx context.Context, repository, digest string) ([]byte, error) {
+	if c.cache != nil {
+		if data, ok := c.cache.Get(digest); ok {
+			return data, nil
+		}
+	}
+
+	apiURL := c.endpoint(ctx, fmt.Sprintf("/v2/%s/blobs/%s", repository, digest))
+
+	req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("获取blob失败: %w", newResponseError(resp))
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.cache != nil {
+		c.cache.Put(digest, data)
+	}
+	return data, nil
+}
+
+// isDigest 判断reference是否为digest（如 sha256:...）
+func isDigest(reference string) bool {
+	return strings.Contains(reference, ":")
+}
+
+// detectManifestMediaType 根据manifest内容推断媒体类型
+// 用于缓存命中或registry未返回Content-Type的情况
+func detectManifestMediaType(body []byte) string {
+	var probe struct {
+		SchemaVersion int               `json:"schemaVersion"`
+		MediaType     string            `json:"mediaType"`
+		Manifests     []json.RawMessage `json:"manifests"`
+		Config        json.RawMessage   `json:"config"`
+		FSLayers      []json.RawMessage `json:"fsLayers"`
+	}
+	if err := json.Unmarshal(body, &probe); err != nil {
+		return ""
+	}
+
+	switch {
+	case probe.MediaType != "":
+		return probe.MediaType
+	case probe.SchemaVersion == 1 || probe.FSLayers != nil:
+		return MediaTypeDockerSchema1Signed
+	case probe.Manifests != nil:
+		return MediaTypeOCIIndex
+	case probe.Config != nil:
+		return MediaTypeOCIManifest
+	}
+	return ""
+}
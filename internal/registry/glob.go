@@ -0,0 +1,163 @@
+package registry
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Glob 编译后的shell风格通配符模式，匹配整个字符串。
+// 其中 * 匹配不包含 / 的任意字符串，** 匹配任意字符串（可以跨越 /，a/**/b 也匹配 a/b），
+// ? 匹配除 / 以外的单个字符，[abc] 匹配字符集合中的一个字符（支持范围 [a-z] 和取反 [!abc]、[^abc]），
+// {a,b} 匹配任意一个备选模式（可以嵌套），\x 匹配字符 x 本身
+type Glob struct {
+	pattern string
+	re      *regexp.Regexp
+}
+
+// CompileGlob 编译通配符模式，ignoreCase 为true时忽略大小写
+func CompileGlob(pattern string, ignoreCase bool) (*Glob, error) {
+	p := &globParser{pattern: pattern}
+	expr, err := p.parse(false)
+	if err != nil {
+		return nil, fmt.Errorf("无效的通配符模式 %q: %v", pattern, err)
+	}
+
+	flags := ""
+	if ignoreCase {
+		flags = "(?i)"
+	}
+	re, err := regexp.Compile(flags + "^" + expr + "$")
+	if err != nil {
+		return nil, fmt.Errorf("无效的通配符模式 %q: %v", pattern, err)
+	}
+	return &Glob{pattern: pattern, re: re}, nil
+}
+
+// Match 判断字符串是否完整匹配模式
+func (g *Glob) Match(s string) bool {
+	return g.re.MatchString(s)
+}
+
+// String 返回原始模式
+func (g *Glob) String() string {
+	return g.pattern
+}
+
+// globParser 将通配符模式转换为正则表达式
+type globParser struct {
+	pattern string
+	pos     int
+}
+
+// parse 转换模式直到结尾；inBrace 为true时在 , 或 } 处停止，由调用方处理
+func (p *globParser) parse(inBrace bool) (string, error) {
+	var b strings.Builder
+	for p.pos < len(p.pattern) {
+		ch := p.pattern[p.pos]
+		switch ch {
+		case '*':
+			if strings.HasPrefix(p.pattern[p.pos:], "**") {
+				p.pos += 2
+				// **/ 匹配零个或多个路径段
+				if p.pos < len(p.pattern) && p.pattern[p.pos] == '/' {
+					p.pos++
+					b.WriteString("(?:.*/)?")
+				} else {
+					b.WriteString(".*")
+				}
+				continue
+			}
+			b.WriteString("[^/]*")
+		case '?':
+			b.WriteString("[^/]")
+		case '[':
+			class, err := p.parseClass()
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(class)
+			continue
+		case '{':
+			p.pos++
+			alternatives, err := p.parseBrace()
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(alternatives)
+			continue
+		case ',', '}':
+			if inBrace {
+				return b.String(), nil
+			}
+			b.WriteString(regexp.QuoteMeta(p.pattern[p.pos : p.pos+1]))
+		case '\\':
+			p.pos++
+			if p.pos >= len(p.pattern) {
+				return "", fmt.Errorf("模式以 \\ 结尾")
+			}
+			b.WriteString(regexp.QuoteMeta(p.pattern[p.pos : p.pos+1]))
+		default:
+			b.WriteString(regexp.QuoteMeta(p.pattern[p.pos : p.pos+1]))
+		}
+		p.pos++
+	}
+	if inBrace {
+		return "", fmt.Errorf("缺少 }")
+	}
+	return b.String(), nil
+}
+
+// parseBrace 转换 { 之后的备选模式，直到对应的 }
+func (p *globParser) parseBrace() (string, error) {
+	var alternatives []string
+	for {
+		alternative, err := p.parse(true)
+		if err != nil {
+			return "", err
+		}
+		alternatives = append(alternatives, alternative)
+
+		ch := p.pattern[p.pos]
+		p.pos++
+		if ch == '}' {
+			return "(?:" + strings.Join(alternatives, "|") + ")", nil
+		}
+	}
+}
+
+// parseClass 转换 [...] 字符集合，集合中的字符都按字面含义处理
+func (p *globParser) parseClass() (string, error) {
+	start := p.pos
+	p.pos++
+
+	var b strings.Builder
+	b.WriteString("[")
+	if p.pos < len(p.pattern) && (p.pattern[p.pos] == '!' || p.pattern[p.pos] == '^') {
+		// 取反的集合同样不匹配 /
+		b.WriteString("^/")
+		p.pos++
+	}
+	// 紧跟在 [ 或 [! 之后的 ] 是普通字符
+	first := true
+	for p.pos < len(p.pattern) {
+		ch := p.pattern[p.pos]
+		if ch == ']' && !first {
+			p.pos++
+			b.WriteString("]")
+			return b.String(), nil
+		}
+		first = false
+		switch {
+		case ch == '\\' && p.pos+1 < len(p.pattern):
+			p.pos++
+			b.WriteString(regexp.QuoteMeta(p.pattern[p.pos : p.pos+1]))
+		case ch == '\\' || ch == '[' || ch == ']' || ch == '^':
+			b.WriteString(`\` + p.pattern[p.pos:p.pos+1])
+		default:
+			b.WriteByte(ch)
+		}
+		p.pos++
+	}
+	return "", fmt.Errorf("位置 %d 的 [ 缺少对应的 ]", start+1)
+}
@@ -0,0 +1,110 @@
+package registry
+
+import "testing"
+
+func TestGlobMatch(t *testing.T) {
+	tests := []struct {
+		pattern, input string
+		want           bool
+	}{
+		// 完整匹配，没有通配符时按字面含义处理
+		{"php", "php", true},
+		{"php", "php-fpm", false},
+		{"php", "library/php", false},
+		{"8.1", "8.1", true},
+		{"8.1", "821", false},
+
+		// * 不跨越 /
+		{"ph*", "php", true},
+		{"ph*", "ph", true},
+		{"ph*", "library/php", false},
+		{"*/php", "library/php", true},
+		{"*/php", "a/b/php", false},
+		{"*", "a/b", false},
+
+		// ** 跨越 /，**/ 也匹配零个路径段
+		{"**", "a/b/c", true},
+		{"library/**", "library/php", true},
+		{"library/**", "library/a/php", true},
+		{"a/**/b", "a/b", true},
+		{"a/**/b", "a/x/y/b", true},
+		{"a/**/b", "a/xb", false},
+		{"**/php", "php", true},
+		{"**/php", "x/y/php", true},
+
+		// ? 匹配除 / 以外的单个字符
+		{"php:8.?", "php:8.1", true},
+		{"php:8.?", "php:8.10", false},
+		{"a?b", "a/b", false},
+
+		// 字符集合
+		{"[abc]", "b", true},
+		{"[abc]", "d", false},
+		{"[a-c]x", "bx", true},
+		{"[!abc]", "d", true},
+		{"[!abc]", "a", false},
+		{"[^abc]", "d", true},
+		{"[!a]", "/", false},
+		{"[]]", "]", true},
+		{"[!]]", "a", true},
+		{"[!]]", "]", false},
+		{"[a^]", "^", true},
+		{"[\\]]", "]", true},
+		{"[.]", ".", true},
+		{"[.]", "x", false},
+
+		// 备选模式，可以嵌套
+		{"php:8.{1,2}-*", "php:8.1-fpm", true},
+		{"php:8.{1,2}-*", "php:8.2-cli", true},
+		{"php:8.{1,2}-*", "php:8.3-fpm", false},
+		{"{a,b{c,d}}", "bd", true},
+		{"{a,b{c,d}}", "b", false},
+		{"{a,}x", "x", true},
+		{"{*.go,[xy]}", "main.go", true},
+		{"{*.go,[xy]}", "y", true},
+
+		// 转义
+		{"\\*", "*", true},
+		{"\\*", "a", false},
+		{"a\\{b,c\\}", "a{b,c}", true},
+		{"a,b}", "a,b}", true},
+		{"(a|b)+", "(a|b)+", true},
+		{"(a|b)+", "a", false},
+	}
+	for _, tt := range tests {
+		g, err := CompileGlob(tt.pattern, false)
+		if err != nil {
+			t.Errorf("CompileGlob(%q) 返回错误: %v", tt.pattern, err)
+			continue
+		}
+		if got := g.Match(tt.input); got != tt.want {
+			t.Errorf("%q 匹配 %q = %v，期望 %v", tt.pattern, tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestGlobIgnoreCase(t *testing.T) {
+	g, err := CompileGlob("PH*:8.[A-C]", true)
+	if err != nil {
+		t.Fatalf("CompileGlob 返回错误: %v", err)
+	}
+	if !g.Match("php:8.b") {
+		t.Errorf("忽略大小写时 %q 应该匹配 %q", g, "php:8.b")
+	}
+
+	g, err = CompileGlob("PH*", false)
+	if err != nil {
+		t.Fatalf("CompileGlob 返回错误: %v", err)
+	}
+	if g.Match("php") {
+		t.Errorf("区分大小写时 %q 不应该匹配 %q", g, "php")
+	}
+}
+
+func TestCompileGlobInvalid(t *testing.T) {
+	for _, pattern := range []string{"[abc", "[!", "{a,b", "a{b{c}", "abc\\", "[z-a]"} {
+		if _, err := CompileGlob(pattern, false); err == nil {
+			t.Errorf("CompileGlob(%q) 期望返回错误", pattern)
+		}
+	}
+}
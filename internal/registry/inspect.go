@@ -0,0 +1,169 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+)
+
+// ImageInspect 表示镜像的详细信息：manifest/index、config 和构建历史
+type ImageInspect struct {
+	Repository string `json:"repository"`
+	Reference  string `json:"reference"`
+	// Digest 和 MediaType 为reference直接指向的内容（可能是index）
+	Digest    string `json:"digest"`
+	MediaType string `json:"mediaType"`
+	Raw       []byte `json:"-"`
+
+	// Index 多平台镜像的index，单平台镜像为nil
+	Index *ImageIndex `json:"index,omitempty"`
+
+	// 选中平台的manifest
+	Platform       string         `json:"platform"`
+	ManifestDigest string         `json:"manifestDigest"`
+	Manifest       *ImageManifest `json:"manifest,omitempty"`
+	ManifestRaw    []byte         `json:"-"`
+
+	Created string         `json:"created"`
+	Config  *ImageConfig   `json:"config,omitempty"`
+	History []LayerHistory `json:"history"`
+}
+
+// LayerHistory 表示构建历史中的一步及其对应层的大小
+type LayerHistory struct {
+	Created    string `json:"created"`
+	CreatedBy  string `json:"createdBy"`
+	Comment    string `json:"comment,omitempty"`
+	EmptyLayer bool   `json:"emptyLayer"`
+	Digest     string `json:"digest,omitempty"`
+	Size       int64  `json:"size"`
+}
+
+// InspectImage 获取镜像的manifest、config和构建历史
+// reference 可以是标签或digest；platform 用于从多平台index中选择平台，为空时优先选择当前主机的架构
+func (c *Client) InspectImage(ctx context.Context, repository, reference, platform string) (*ImageInspect, error) {
+	if err := validatePlatformFilter(platform); err != nil {
+		return nil, err
+	}
+
+	// 检查是否有有效的认证信息
+	if !c.HasValidCredentials(ctx) {
+		return nil, fmt.Errorf("未找到有效的认证信息，请先使用 'docker genee login' 登录")
+	}
+
+	manifest, err := c.getManifest(ctx, repository, reference)
+	if err != nil {
+		return nil, err
+	}
+
+	inspect := &ImageInspect{
+		Repository:     repository,
+		Reference:      reference,
+		Digest:         manifest.Digest,
+		MediaType:      manifest.MediaType,
+		Raw:            manifest.Raw,
+		ManifestDigest: manifest.Digest,
+		ManifestRaw:    manifest.Raw,
+	}
+
+	switch {
+	case manifest.Index != nil:
+		inspect.Index = manifest.Index
+
+		// 从index中选择平台
+		descriptor, err := selectPlatformManifest(manifest.Index, platform)
+		if err != nil {
+			return nil, err
+		}
+		inspect.Platform = descriptor.Platform.String()
+		inspect.ManifestDigest = descriptor.Digest
+
+		platformManifest, err := c.getManifest(ctx, repository, descriptor.Digest)
+		if err != nil {
+			return nil, fmt.Errorf("平台 %s: %w", inspect.Platform, err)
+		}
+		if platformManifest.Manifest == nil {
+			return nil, fmt.Errorf("平台 %s 的清单类型不受支持: %s", inspect.Platform, platformManifest.MediaType)
+		}
+		inspect.Manifest = platformManifest.Manifest
+		inspect.ManifestRaw = platformManifest.Raw
+	case manifest.Manifest != nil:
+		inspect.Manifest = manifest.Manifest
+	default:
+		return nil, fmt.Errorf("不支持查看该类型的清单: %s", manifest.MediaType)
+	}
+
+	config, err := c.getImageConfig(ctx, repository, inspect.Manifest.Config.Digest)
+	if err != nil {
+		return nil, fmt.Errorf("获取config失败: %w", err)
+	}
+	inspect.Config = config
+	inspect.Created, _ = formatCreated(config.Created)
+
+	if inspect.Platform == "" {
+		inspect.Platform = config.Platform().String()
+		if platform != "" && !matchesPlatform(inspect.Platform, platform) {
+			return nil, fmt.Errorf("镜像不支持平台 %s（镜像平台: %s）", platform, inspect.Platform)
+		}
+	}
+
+	inspect.History = layerHistory(config.History, inspect.Manifest.Layers)
+	return inspect, nil
+}
+
+// selectPlatformManifest 从index中选择平台对应的manifest
+// 指定了平台时选择第一个匹配的平台，否则优先当前主机的架构，再退回第一个有效平台
+func selectPlatformManifest(index *ImageIndex, platform string) (*Descriptor, error) {
+	descriptors := indexPlatformManifests(index)
+	if len(descriptors) == 0 {
+		return nil, fmt.Errorf("index中没有有效的平台")
+	}
+
+	if platform != "" {
+		for i := range descriptors {
+			if matchesPlatform(descriptors[i].Platform.String(), platform) {
+				return &descriptors[i], nil
+			}
+		}
+		return nil, fmt.Errorf("镜像不支持平台 %s", platform)
+	}
+
+	for i := range descriptors {
+		if descriptors[i].Platform.OS == "linux" && descriptors[i].Platform.Architecture == runtime.GOARCH {
+			return &descriptors[i], nil
+		}
+	}
+	return &descriptors[0], nil
+}
+
+// layerHistory 将构建历史与manifest中的层按顺序对应起来
+// 只有非空层（empty_layer 为false）才对应manifest中的一个层
+func layerHistory(history []History, layers []Descriptor) []LayerHistory {
+	var result []LayerHistory
+	layerIndex := 0
+	for _, h := range history {
+		entry := LayerHistory{
+			Created:    h.Created,
+			CreatedBy:  h.CreatedBy,
+			Comment:    h.Comment,
+			EmptyLayer: h.EmptyLayer,
+		}
+		if created, ok := formatCreated(h.Created); ok {
+			entry.Created = created
+		}
+		if !h.EmptyLayer && layerIndex < len(layers) {
+			entry.Digest = layers[layerIndex].Digest
+			entry.Size = layers[layerIndex].Size
+			layerIndex++
+		}
+		result = append(result, entry)
+	}
+
+	// 没有构建历史时，直接列出所有层
+	if len(history) == 0 {
+		for _, layer := range layers {
+			result = append(result, LayerHistory{Digest: layer.Digest, Size: layer.Size})
+		}
+	}
+	return result
+}
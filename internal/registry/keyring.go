@@ -0,0 +1,96 @@
+package registry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// keyringService 保存到系统密钥环时使用的服务名
+const keyringService = "docker-genee"
+
+// keyringStore 通过 secret-tool 使用Secret Service（GNOME Keyring、KWallet等）保存凭证
+type keyringStore struct{}
+
+func newKeyringStore() *keyringStore {
+	return &keyringStore{}
+}
+
+// available 判断系统密钥环是否可用：需要安装 secret-tool 且存在D-Bus会话
+func (s *keyringStore) available() bool {
+	if os.Getenv("DBUS_SESSION_BUS_ADDRESS") == "" {
+		return false
+	}
+	_, err := exec.LookPath("secret-tool")
+	return err == nil
+}
+
+func (s *keyringStore) Name() string {
+	return "系统密钥环"
+}
+
+func (s *keyringStore) Load(ctx context.Context, registry string) (*Credentials, error) {
+	output, err := s.run(ctx, "", "lookup", "service", keyringService, "registry", registry)
+	if err != nil {
+		return nil, err
+	}
+	// secret-tool lookup 在没有匹配项时不输出内容
+	if len(bytes.TrimSpace(output)) == 0 {
+		return nil, errCredentialsNotFound
+	}
+
+	var creds Credentials
+	if err := json.Unmarshal(output, &creds); err != nil {
+		return nil, fmt.Errorf("解析密钥环中的凭证失败: %v", err)
+	}
+	return &creds, nil
+}
+
+func (s *keyringStore) Save(ctx context.Context, registry string, creds *Credentials) error {
+	data, err := json.Marshal(creds)
+	if err != nil {
+		return err
+	}
+	label := fmt.Sprintf("docker-genee: %s", registry)
+	_, err = s.run(ctx, string(data), "store", "--label", label, "service", keyringService, "registry", registry)
+	return err
+}
+
+func (s *keyringStore) Delete(ctx context.Context, registry string) (bool, error) {
+	if _, err := s.Load(ctx, registry); err != nil {
+		if err == errCredentialsNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+	if _, err := s.run(ctx, "", "clear", "service", keyringService, "registry", registry); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// run 执行 secret-tool 命令，密码通过标准输入传入，避免出现在进程列表中
+func (s *keyringStore) run(ctx context.Context, input string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "secret-tool", args...)
+	cmd.Stdin = strings.NewReader(input)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		// lookup 在没有匹配项时以非零状态退出
+		if args[0] == "lookup" && strings.TrimSpace(stderr.String()) == "" {
+			return nil, nil
+		}
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, fmt.Errorf("secret-tool %s 失败: %s", args[0], message)
+		}
+		return nil, fmt.Errorf("secret-tool %s 失败: %v", args[0], err)
+	}
+	return stdout.Bytes(), nil
+}
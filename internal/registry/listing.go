@@ -0,0 +1,415 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SortField 镜像列表的排序字段
+type SortField string
+
+// 支持的排序字段，为空时保持仓库在registry目录中的顺序
+const (
+	SortByName    SortField = "name"
+	SortByCreated SortField = "created"
+	SortBySize    SortField = "size"
+	SortByTags    SortField = "tags"
+)
+
+// ParseSortField 解析排序字段，空字符串表示不排序
+func ParseSortField(s string) (SortField, error) {
+	switch field := SortField(strings.ToLower(strings.TrimSpace(s))); field {
+	case "", SortByName, SortByCreated, SortBySize, SortByTags:
+		return field, nil
+	}
+	return "", fmt.Errorf("无效的排序字段 %q，可选值: created、size、name、tags", s)
+}
+
+// LabelFilter 按镜像config中的标签过滤，HasValue 为false时只要求存在该标签
+type LabelFilter struct {
+	Key      string
+	Value    string
+	HasValue bool
+}
+
+// Filter docker风格的过滤条件，各类条件需要同时满足，多个 reference 满足任意一个即可
+type Filter struct {
+	// Since 和 Before 只保留创建时间晚于/早于该镜像（repository[:tag]）的镜像
+	Since  string
+	Before string
+	// Labels 要求镜像同时带有这些标签
+	Labels []LabelFilter
+	// References 仓库名的通配符模式（见 Glob），包含 :标签 时匹配 仓库:标签
+	References []string
+}
+
+// filterKeys 支持的过滤条件名称
+var filterKeys = []string{"since", "before", "label", "reference"}
+
+// ParseFilters 解析 key=value 形式的过滤条件，每一项也可以包含多个用逗号分隔的条件，
+// 如 since=app:1.0,label=maintainer；不以已知条件名开头的部分属于前一个条件的值（如 reference=php:{7,8}*）
+func ParseFilters(args []string) (Filter, error) {
+	var items []string
+	for _, arg := range args {
+		for i, part := range strings.Split(arg, ",") {
+			if i > 0 && !isFilterItem(part) {
+				items[len(items)-1] += "," + part
+				continue
+			}
+			items = append(items, part)
+		}
+	}
+
+	var f Filter
+	for _, item := range items {
+		key, value, ok := strings.Cut(strings.TrimSpace(item), "=")
+		if !ok || value == "" {
+			return Filter{}, fmt.Errorf("无效的过滤条件 %q: 格式应为 key=value", item)
+		}
+		switch strings.ToLower(key) {
+		case "since":
+			if f.Since != "" {
+				return Filter{}, fmt.Errorf("过滤条件 since 只能指定一次")
+			}
+			f.Since = value
+		case "before":
+			if f.Before != "" {
+				return Filter{}, fmt.Errorf("过滤条件 before 只能指定一次")
+			}
+			f.Before = value
+		case "label":
+			label := LabelFilter{Key: value}
+			if k, v, ok := strings.Cut(value, "="); ok {
+				label = LabelFilter{Key: k, Value: v, HasValue: true}
+			}
+			f.Labels = append(f.Labels, label)
+		case "reference":
+			if _, err := CompileGlob(value, false); err != nil {
+				return Filter{}, err
+			}
+			f.References = append(f.References, value)
+		default:
+			return Filter{}, fmt.Errorf("不支持的过滤条件 %q，可选值: %s", key, strings.Join(filterKeys, "、"))
+		}
+	}
+	return f, nil
+}
+
+// isFilterItem 判断逗号之后的部分是否是新的过滤条件
+func isFilterItem(s string) bool {
+	key, _, ok := strings.Cut(strings.TrimSpace(s), "=")
+	if !ok {
+		return false
+	}
+	for _, k := range filterKeys {
+		if strings.EqualFold(key, k) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsEmpty 判断是否没有任何过滤条件
+func (f Filter) IsEmpty() bool {
+	return f.Since == "" && f.Before == "" && len(f.Labels) == 0 && len(f.References) == 0
+}
+
+// listEntry 排序和过滤时使用的一行结果
+type listEntry struct {
+	repository string
+	tag        string
+	// platform 用于读取多平台镜像的标签，与显示的大小和创建时间对应
+	platform string
+	created  string
+	size     int64
+	tags     int
+}
+
+// listRow 可以排序和过滤的结果类型
+type listRow interface {
+	entry() listEntry
+}
+
+// entry 返回镜像用于排序和过滤的信息
+func (img Image) entry() listEntry {
+	return listEntry{
+		repository: img.Repository,
+		tag:        img.Tag,
+		platform:   img.platform,
+		created:    img.Created,
+		size:       img.sizeBytes,
+		tags:       img.tagCount,
+	}
+}
+
+// entry 返回搜索结果用于排序和过滤的信息，按主要标签（LatestTag）过滤和排序
+func (r SearchResult) entry() listEntry {
+	return listEntry{
+		repository: r.Name,
+		tag:        r.LatestTag,
+		platform:   r.platform,
+		created:    r.Created,
+		size:       r.sizeBytes,
+		tags:       r.Tags,
+	}
+}
+
+// rowCount 返回搜索结果在输出中占的行数，每个匹配的标签一行
+func (r SearchResult) rowCount() int {
+	if len(r.MatchedTags) > 0 {
+		return len(r.MatchedTags)
+	}
+	return 1
+}
+
+// limitSearchResults 按输出的行数截断搜索结果，最后一个结果只保留剩余数量的匹配标签
+func limitSearchResults(results []SearchResult, limit int) []SearchResult {
+	if limit <= 0 {
+		return results
+	}
+	rows := 0
+	for i := range results {
+		n := results[i].rowCount()
+		if rows+n >= limit {
+			if len(results[i].MatchedTags) > 0 {
+				results[i].MatchedTags = results[i].MatchedTags[:limit-rows]
+			}
+			return results[:i+1]
+		}
+		rows += n
+	}
+	return results
+}
+
+// referenceFilter 编译后的 reference 条件
+type referenceFilter struct {
+	glob    *Glob
+	withTag bool
+}
+
+// listFilter 已解析的过滤条件，since/before 已转换为对应镜像的创建时间
+type listFilter struct {
+	since, before time.Time
+	labels        []LabelFilter
+	references    []referenceFilter
+}
+
+// resolveFilter 获取 since/before 引用的镜像的创建时间并编译 reference 模式，没有过滤条件时返回nil
+// 多平台镜像的创建时间取 platform 对应的平台，与列表中显示的时间一致
+func (c *Client) resolveFilter(ctx context.Context, f Filter, platform string) (*listFilter, error) {
+	if f.IsEmpty() {
+		return nil, nil
+	}
+
+	lf := &listFilter{labels: f.Labels}
+	for _, ref := range []struct {
+		name, image string
+		target      *time.Time
+	}{{"since", f.Since, &lf.since}, {"before", f.Before, &lf.before}} {
+		if ref.image == "" {
+			continue
+		}
+		repository, tag := splitImageReference(ref.image)
+		manifest, err := c.getImageManifest(ctx, repository, tag, platform)
+		if err != nil {
+			return nil, fmt.Errorf("无法获取 %s 指定的镜像 %s: %w", ref.name, ref.image, err)
+		}
+		created, err := time.Parse(TimeFormat, manifest.Created)
+		if err != nil {
+			return nil, fmt.Errorf("无法获取 %s 指定的镜像 %s 的创建时间", ref.name, ref.image)
+		}
+		*ref.target = created
+	}
+
+	for _, pattern := range f.References {
+		glob, err := CompileGlob(pattern, false)
+		if err != nil {
+			return nil, err
+		}
+		withTag := strings.LastIndex(pattern, ":") > strings.LastIndex(pattern, "/")
+		lf.references = append(lf.references, referenceFilter{glob: glob, withTag: withTag})
+	}
+	return lf, nil
+}
+
+// splitImageReference 将 repository[:tag] 拆分为仓库和标签，未指定标签时为 latest
+func splitImageReference(ref string) (string, string) {
+	if i := strings.LastIndex(ref, ":"); i > strings.LastIndex(ref, "/") {
+		return ref[:i], ref[i+1:]
+	}
+	return ref, "latest"
+}
+
+// match 判断一行结果是否满足所有过滤条件，标签条件需要读取镜像的config
+// 创建时间未知的行不满足 since/before 条件
+func (c *Client) match(ctx context.Context, f *listFilter, e listEntry) bool {
+	if !f.since.IsZero() || !f.before.IsZero() {
+		created, err := time.Parse(TimeFormat, e.created)
+		if err != nil {
+			return false
+		}
+		if !f.since.IsZero() && !created.After(f.since) {
+			return false
+		}
+		if !f.before.IsZero() && !created.Before(f.before) {
+			return false
+		}
+	}
+
+	if len(f.references) > 0 {
+		matched := false
+		for _, ref := range f.references {
+			name := e.repository
+			if ref.withTag {
+				name += ":" + e.tag
+			}
+			if ref.glob.Match(name) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	if len(f.labels) > 0 {
+		labels := c.getImageLabels(ctx, e.repository, e.tag, e.platform)
+		for _, label := range f.labels {
+			value, ok := labels[label.Key]
+			if !ok || (label.HasValue && value != label.Value) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// getImageLabels 获取镜像config中的标签，多平台镜像取 platform 对应的平台（没有匹配时为当前主机的平台）
+func (c *Client) getImageLabels(ctx context.Context, repository, tag, platform string) map[string]string {
+	manifest, err := c.getManifest(ctx, repository, tag)
+	if err != nil {
+		return nil
+	}
+
+	if manifest.Index != nil {
+		descriptor, err := selectPlatformManifest(manifest.Index, platform)
+		if err != nil {
+			descriptor, err = selectPlatformManifest(manifest.Index, "")
+		}
+		if err != nil {
+			return nil
+		}
+		if manifest, err = c.getManifest(ctx, repository, descriptor.Digest); err != nil {
+			return nil
+		}
+	}
+
+	switch {
+	case manifest.Manifest != nil:
+		config, err := c.getImageConfig(ctx, repository, manifest.Manifest.Config.Digest)
+		if err != nil {
+			return nil
+		}
+		return config.Config.Labels
+	case manifest.V1 != nil:
+		if images := manifest.V1.v1Images(); len(images) > 0 {
+			return images[0].Config.Labels
+		}
+	}
+	return nil
+}
+
+// filterRows 并发检查每一行是否满足过滤条件，保持原有顺序；f 为nil时不过滤
+func filterRows[T listRow](ctx context.Context, c *Client, rows []T, f *listFilter) []T {
+	if f == nil {
+		return rows
+	}
+	keep := make([]bool, len(rows))
+	c.runConcurrently(ctx, len(rows), func(i int) {
+		keep[i] = c.match(ctx, f, rows[i].entry())
+	})
+
+	var filtered []T
+	for i, row := range rows {
+		if keep[i] {
+			filtered = append(filtered, row)
+		}
+	}
+	return filtered
+}
+
+// sortRows 按字段排序：名称从A到Z，创建时间从新到旧，大小和标签数量从大到小；
+// reverse 为true时顺序相反，未指定字段时只反转原有顺序。相同的行保持原有顺序
+func sortRows[T listRow](rows []T, field SortField, reverse bool) {
+	var less func(a, b listEntry) bool
+	switch field {
+	case SortByName:
+		less = func(a, b listEntry) bool {
+			if a.repository != b.repository {
+				return a.repository < b.repository
+			}
+			return a.tag < b.tag
+		}
+	case SortByCreated:
+		// 时间格式固定为 TimeFormat，可以直接按字符串比较
+		less = func(a, b listEntry) bool { return a.created > b.created }
+	case SortBySize:
+		less = func(a, b listEntry) bool { return a.size > b.size }
+	case SortByTags:
+		less = func(a, b listEntry) bool { return a.tags > b.tags }
+	default:
+		if reverse {
+			for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+				rows[i], rows[j] = rows[j], rows[i]
+			}
+		}
+		return
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := rows[i].entry(), rows[j].entry()
+		// 创建时间未知的行不论顺序如何都排在最后
+		if field == SortByCreated && (a.created == "") != (b.created == "") {
+			return b.created == ""
+		}
+		if reverse {
+			a, b = b, a
+		}
+		return less(a, b)
+	})
+}
+
+// fetchRows 并发获取 n 项的结果并按顺序拼接
+// limit > 0 时分批获取，每批的数量为还缺少的行数，得到 limit 行后不再获取剩余的项；
+// count 返回一项结果在输出中占的行数
+func fetchRows[T any](ctx context.Context, c *Client, n, limit int, count func(T) int, fetch func(i int) []T) []T {
+	var rows []T
+	total := 0
+	for next := 0; next < n && ctx.Err() == nil; {
+		batch := n - next
+		if limit > 0 {
+			if total >= limit {
+				break
+			}
+			batch = min(batch, limit-total)
+		}
+
+		results := make([][]T, batch)
+		start := next
+		c.runConcurrently(ctx, batch, func(i int) {
+			results[i] = fetch(start + i)
+		})
+		for _, result := range results {
+			for _, row := range result {
+				rows = append(rows, row)
+				total += count(row)
+			}
+		}
+		next += batch
+	}
+	return rows
+}
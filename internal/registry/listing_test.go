@@ -0,0 +1,146 @@
+package registry
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseFilters(t *testing.T) {
+	tests := []struct {
+		args []string
+		want Filter
+	}{
+		{nil, Filter{}},
+		{[]string{"since=app:1.0"}, Filter{Since: "app:1.0"}},
+		{[]string{"before=app"}, Filter{Before: "app"}},
+		{[]string{"label=maintainer"}, Filter{Labels: []LabelFilter{{Key: "maintainer"}}}},
+		{[]string{"label=tier=web"}, Filter{Labels: []LabelFilter{{Key: "tier", Value: "web", HasValue: true}}}},
+		{[]string{"LABEL=a", "Reference=php*"}, Filter{Labels: []LabelFilter{{Key: "a"}}, References: []string{"php*"}}},
+		// 一项中包含多个用逗号分隔的条件
+		{[]string{"since=app:1.0,label=maintainer"}, Filter{Since: "app:1.0", Labels: []LabelFilter{{Key: "maintainer"}}}},
+		{[]string{"reference=a, reference=b"}, Filter{References: []string{"a", "b"}}},
+		// 不以条件名开头的部分属于前一个条件的值
+		{[]string{"reference=php:{7,8}*"}, Filter{References: []string{"php:{7,8}*"}}},
+		{[]string{"reference=php:{7,8}*,label=a"}, Filter{Labels: []LabelFilter{{Key: "a"}}, References: []string{"php:{7,8}*"}}},
+		{[]string{"reference={a,b,c}/*,since=x"}, Filter{Since: "x", References: []string{"{a,b,c}/*"}}},
+		{[]string{"label=desc=a,b"}, Filter{Labels: []LabelFilter{{Key: "desc", Value: "a,b", HasValue: true}}}},
+	}
+	for _, tt := range tests {
+		got, err := ParseFilters(tt.args)
+		if err != nil {
+			t.Errorf("ParseFilters(%q) 返回错误: %v", tt.args, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseFilters(%q) = %+v，期望 %+v", tt.args, got, tt.want)
+		}
+	}
+
+	invalid := [][]string{
+		{"since"},
+		{"since="},
+		{"=app"},
+		{"dangling=true"},
+		{"since=a", "since=b"},
+		{"before=a,before=b"},
+		{"reference=php:[78"},
+		{"reference=php:{7,8"},
+	}
+	for _, args := range invalid {
+		if _, err := ParseFilters(args); err == nil {
+			t.Errorf("ParseFilters(%q) 期望返回错误", args)
+		}
+	}
+}
+
+func TestLimitSearchResults(t *testing.T) {
+	// 每个结果按匹配的标签数占多行，没有匹配标签时占一行
+	newResults := func() []SearchResult {
+		return []SearchResult{
+			{Name: "a"},
+			{Name: "b", MatchedTags: []string{"1", "2", "3"}},
+			{Name: "c", MatchedTags: []string{"4", "5"}},
+		}
+	}
+
+	tests := []struct {
+		limit int
+		names []string
+		tags  []string
+	}{
+		{0, []string{"a", "b", "c"}, []string{"4", "5"}},
+		{-1, []string{"a", "b", "c"}, []string{"4", "5"}},
+		{1, []string{"a"}, nil},
+		{2, []string{"a", "b"}, []string{"1"}},
+		{4, []string{"a", "b"}, []string{"1", "2", "3"}},
+		{5, []string{"a", "b", "c"}, []string{"4"}},
+		{6, []string{"a", "b", "c"}, []string{"4", "5"}},
+		{100, []string{"a", "b", "c"}, []string{"4", "5"}},
+	}
+	for _, tt := range tests {
+		results := limitSearchResults(newResults(), tt.limit)
+		var names []string
+		for _, r := range results {
+			names = append(names, r.Name)
+		}
+		last := results[len(results)-1].MatchedTags
+		if !reflect.DeepEqual(names, tt.names) || !reflect.DeepEqual(last, tt.tags) {
+			t.Errorf("limitSearchResults(%d) = %v %v，期望 %v %v", tt.limit, names, last, tt.names, tt.tags)
+		}
+	}
+}
+
+func TestSortRows(t *testing.T) {
+	newImages := func() []Image {
+		return []Image{
+			{Repository: "b", Tag: "1", Created: "2024-01-01 00:00:00", sizeBytes: 30, tagCount: 1},
+			{Repository: "a", Tag: "2", Created: "2024-03-01 00:00:00", sizeBytes: 10, tagCount: 3},
+			{Repository: "c", Tag: "1", Created: "2024-02-01 00:00:00", sizeBytes: 20, tagCount: 2},
+			{Repository: "a", Tag: "1", Created: "2023-01-01 00:00:00", sizeBytes: 40, tagCount: 3},
+		}
+	}
+
+	tests := []struct {
+		field   SortField
+		reverse bool
+		want    []string
+	}{
+		{"", false, []string{"b:1", "a:2", "c:1", "a:1"}},
+		{"", true, []string{"a:1", "c:1", "a:2", "b:1"}},
+		{SortByName, false, []string{"a:1", "a:2", "b:1", "c:1"}},
+		{SortByName, true, []string{"c:1", "b:1", "a:2", "a:1"}},
+		// 创建时间、大小和标签数默认从大到小
+		{SortByCreated, false, []string{"a:2", "c:1", "b:1", "a:1"}},
+		{SortByCreated, true, []string{"a:1", "b:1", "c:1", "a:2"}},
+		{SortBySize, false, []string{"a:1", "b:1", "c:1", "a:2"}},
+		// 相同的值保持原来的顺序
+		{SortByTags, false, []string{"a:2", "a:1", "c:1", "b:1"}},
+	}
+	for _, tt := range tests {
+		images := newImages()
+		sortRows(images, tt.field, tt.reverse)
+		var got []string
+		for _, img := range images {
+			got = append(got, img.Repository+":"+img.Tag)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("sortRows(%q, reverse=%v) = %v，期望 %v", tt.field, tt.reverse, got, tt.want)
+		}
+	}
+}
+
+func TestSortRowsUnknownCreated(t *testing.T) {
+	// 创建时间未知的行不论顺序如何都排在最后
+	for _, reverse := range []bool{false, true} {
+		images := []Image{
+			{Repository: "unknown"},
+			{Repository: "old", Created: "2023-01-01 00:00:00"},
+			{Repository: "new", Created: "2024-01-01 00:00:00"},
+		}
+		sortRows(images, SortByCreated, reverse)
+		if images[2].Repository != "unknown" {
+			t.Errorf("sortRows(created, reverse=%v) = %s %s %s，期望 unknown 在最后",
+				reverse, images[0].Repository, images[1].Repository, images[2].Repository)
+		}
+	}
+}
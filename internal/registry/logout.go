@@ -0,0 +1,82 @@
+package registry
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+)
+
+// LogoutResult 表示从一个凭证存储中删除认证信息的结果
+type LogoutResult struct {
+	// Store 凭证存储的名称
+	Store string
+	// Removed 是否删除了认证信息
+	Removed bool
+	// Err 删除失败时的错误
+	Err error
+}
+
+// Logout 从所有凭证存储中删除当前registry的认证信息
+// 包括配置的凭证助手、Docker配置文件、本地加密存储和旧版本的明文凭证文件，返回每个存储的处理结果
+func (c *Client) Logout(ctx context.Context) []LogoutResult {
+	var results []LogoutResult
+
+	configPath := c.dockerConfigPath()
+	config, err := loadDockerConfig(configPath)
+	if err != nil {
+		results = append(results, LogoutResult{Store: configPath, Err: err})
+	} else {
+		// 凭证助手
+		if helper := config.credentialHelper(c.registryURL); helper != "" {
+			result := LogoutResult{Store: credentialHelperProgram(helper)}
+			if err := helperErase(ctx, helper, c.registryURL); err == nil {
+				result.Removed = true
+			} else if err != errCredentialsNotFound {
+				result.Err = err
+			}
+			results = append(results, result)
+		}
+
+		// Docker配置文件
+		result := LogoutResult{Store: configPath}
+		if config.removeAuth(c.registryURL) {
+			if err := config.save(); err != nil {
+				result.Err = err
+			} else {
+				result.Removed = true
+			}
+		}
+		results = append(results, result)
+	}
+
+	// 本地加密存储
+	if store, err := c.credentialStore(); err == nil {
+		removed, err := store.Delete(ctx, c.registryURL)
+		results = append(results, LogoutResult{Store: store.Name(), Removed: removed, Err: err})
+	}
+
+	// 旧版本保存的明文凭证文件，其中只有默认镜像源的凭证
+	if c.registryURL == legacyCredentialsRegistry {
+		localPath := c.localCredentialsPath()
+		removed, err := removeFile(localPath)
+		results = append(results, LogoutResult{Store: localPath, Removed: removed, Err: err})
+	}
+
+	c.credentials = nil
+	c.tokenMu.Lock()
+	c.tokens = nil
+	c.tokenMu.Unlock()
+
+	return results
+}
+
+// removeFile 删除文件，文件不存在时不视为错误
+func removeFile(path string) (bool, error) {
+	if err := os.Remove(filepath.Clean(path)); err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,262 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// manifest 和 config 的媒体类型
+const (
+	MediaTypeDockerSchema1       = "application/vnd.docker.distribution.manifest.v1+json"
+	MediaTypeDockerSchema1Signed = "application/vnd.docker.distribution.manifest.v1+prettyjws"
+	MediaTypeDockerSchema2       = "application/vnd.docker.distribution.manifest.v2+json"
+	MediaTypeDockerManifestList  = "application/vnd.docker.distribution.manifest.list.v2+json"
+	MediaTypeOCIManifest         = "application/vnd.oci.image.manifest.v1+json"
+	MediaTypeOCIIndex            = "application/vnd.oci.image.index.v1+json"
+)
+
+// Platform 表示镜像运行的平台
+type Platform struct {
+	Architecture string   `json:"architecture"`
+	OS           string   `json:"os"`
+	OSVersion    string   `json:"os.version,omitempty"`
+	OSFeatures   []string `json:"os.features,omitempty"`
+	Variant      string   `json:"variant,omitempty"`
+}
+
+// String 返回 os/arch 形式的平台名称，有变体时为 os/arch/variant
+func (p Platform) String() string {
+	if p.Variant != "" {
+		return fmt.Sprintf("%s/%s/%s", p.OS, p.Architecture, p.Variant)
+	}
+	return fmt.Sprintf("%s/%s", p.OS, p.Architecture)
+}
+
+// Descriptor 描述manifest中引用的内容（config、layer或子manifest）
+type Descriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	URLs        []string          `json:"urls,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	Platform    *Platform         `json:"platform,omitempty"`
+}
+
+// ImageManifest 表示单平台manifest（Docker v2 schema2 或 OCI image manifest）
+type ImageManifest struct {
+	SchemaVersion int               `json:"schemaVersion"`
+	MediaType     string            `json:"mediaType,omitempty"`
+	ArtifactType  string            `json:"artifactType,omitempty"`
+	Config        Descriptor        `json:"config"`
+	Layers        []Descriptor      `json:"layers"`
+	Annotations   map[string]string `json:"annotations,omitempty"`
+}
+
+// TotalSize 返回config和所有layer的压缩大小之和
+func (m *ImageManifest) TotalSize() int64 {
+	size := m.Config.Size
+	for _, layer := range m.Layers {
+		size += layer.Size
+	}
+	return size
+}
+
+// ImageIndex 表示多平台manifest（Docker manifest list 或 OCI image index）
+type ImageIndex struct {
+	SchemaVersion int               `json:"schemaVersion"`
+	MediaType     string            `json:"mediaType,omitempty"`
+	Manifests     []Descriptor      `json:"manifests"`
+	Annotations   map[string]string `json:"annotations,omitempty"`
+}
+
+// indexPlatformManifests 返回index中带有有效平台信息的manifest
+// 跳过 unknown/unknown 等无效平台（如attestation manifest）
+func indexPlatformManifests(index *ImageIndex) []Descriptor {
+	var descriptors []Descriptor
+	for _, descriptor := range index.Manifests {
+		p := descriptor.Platform
+		if p == nil || p.OS == "" || p.Architecture == "" || p.OS == "unknown" || p.Architecture == "unknown" {
+			continue
+		}
+		descriptors = append(descriptors, descriptor)
+	}
+	return descriptors
+}
+
+// SchemaV1Manifest 表示已废弃的Docker v2 schema1 manifest
+type SchemaV1Manifest struct {
+	SchemaVersion int    `json:"schemaVersion"`
+	Name          string `json:"name"`
+	Tag           string `json:"tag"`
+	Architecture  string `json:"architecture"`
+	FSLayers      []struct {
+		BlobSum string `json:"blobSum"`
+	} `json:"fsLayers"`
+	History []struct {
+		V1Compatibility string `json:"v1Compatibility"`
+	} `json:"history"`
+}
+
+// V1Image 表示schema1 history中 v1Compatibility 字段的内容
+type V1Image struct {
+	ID           string          `json:"id"`
+	Parent       string          `json:"parent,omitempty"`
+	Created      string          `json:"created"`
+	OS           string          `json:"os,omitempty"`
+	Architecture string          `json:"architecture,omitempty"`
+	Config       ContainerConfig `json:"config,omitempty"`
+}
+
+// ImageConfig 表示镜像的config blob
+type ImageConfig struct {
+	Created      string          `json:"created,omitempty"`
+	Author       string          `json:"author,omitempty"`
+	Architecture string          `json:"architecture"`
+	OS           string          `json:"os"`
+	OSVersion    string          `json:"os.version,omitempty"`
+	Variant      string          `json:"variant,omitempty"`
+	Config       ContainerConfig `json:"config,omitempty"`
+	RootFS       struct {
+		Type    string   `json:"type"`
+		DiffIDs []string `json:"diff_ids"`
+	} `json:"rootfs"`
+	History []History `json:"history,omitempty"`
+}
+
+// Platform 返回config中记录的平台
+func (c *ImageConfig) Platform() Platform {
+	return Platform{
+		Architecture: c.Architecture,
+		OS:           c.OS,
+		OSVersion:    c.OSVersion,
+		Variant:      c.Variant,
+	}
+}
+
+// ContainerConfig 表示镜像的运行配置
+type ContainerConfig struct {
+	User         string              `json:"User,omitempty"`
+	ExposedPorts map[string]struct{} `json:"ExposedPorts,omitempty"`
+	Env          []string            `json:"Env,omitempty"`
+	Entrypoint   []string            `json:"Entrypoint,omitempty"`
+	Cmd          []string            `json:"Cmd,omitempty"`
+	Volumes      map[string]struct{} `json:"Volumes,omitempty"`
+	WorkingDir   string              `json:"WorkingDir,omitempty"`
+	Labels       map[string]string   `json:"Labels,omitempty"`
+	StopSignal   string              `json:"StopSignal,omitempty"`
+}
+
+// History 表示镜像构建历史中的一步
+type History struct {
+	Created    string `json:"created,omitempty"`
+	CreatedBy  string `json:"created_by,omitempty"`
+	Author     string `json:"author,omitempty"`
+	Comment    string `json:"comment,omitempty"`
+	EmptyLayer bool   `json:"empty_layer,omitempty"`
+}
+
+// FetchedManifest 表示按媒体类型解析后的manifest，Manifest/Index/V1 中只有一个非空
+type FetchedManifest struct {
+	MediaType string
+	Digest    string
+	Raw       []byte
+
+	Manifest *ImageManifest
+	Index    *ImageIndex
+	V1       *SchemaV1Manifest
+}
+
+// IsIndex 判断是否为多平台manifest
+func (m *FetchedManifest) IsIndex() bool {
+	return m.Index != nil
+}
+
+// getManifest 获取manifest并按媒体类型解析为对应的类型
+func (c *Client) getManifest(ctx context.Context, repository, reference string) (*FetchedManifest, error) {
+	raw, err := c.fetchManifest(ctx, repository, reference)
+	if err != nil {
+		return nil, err
+	}
+	return parseManifest(raw)
+}
+
+// parseManifest 按媒体类型解析原始manifest
+func parseManifest(raw *rawManifest) (*FetchedManifest, error) {
+	fetched := &FetchedManifest{
+		MediaType: raw.MediaType,
+		Digest:    raw.Digest,
+		Raw:       raw.Body,
+	}
+
+	var err error
+	switch raw.MediaType {
+	case MediaTypeDockerManifestList, MediaTypeOCIIndex:
+		fetched.Index = &ImageIndex{}
+		err = json.Unmarshal(raw.Body, fetched.Index)
+	case MediaTypeDockerSchema2, MediaTypeOCIManifest:
+		fetched.Manifest = &ImageManifest{}
+		err = json.Unmarshal(raw.Body, fetched.Manifest)
+	case MediaTypeDockerSchema1, MediaTypeDockerSchema1Signed:
+		fetched.V1 = &SchemaV1Manifest{}
+		err = json.Unmarshal(raw.Body, fetched.V1)
+	default:
+		// 未知的Content-Type，根据内容再判断一次
+		detected := detectManifestMediaType(raw.Body)
+		if detected == "" || detected == raw.MediaType {
+			return nil, fmt.Errorf("不支持的清单类型: %s", raw.MediaType)
+		}
+		return parseManifest(&rawManifest{Digest: raw.Digest, MediaType: detected, Body: raw.Body})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("解析清单失败: %v", err)
+	}
+	return fetched, nil
+}
+
+// getImageConfig 获取并解析镜像的config blob
+func (c *Client) getImageConfig(ctx context.Context, repository, digest string) (*ImageConfig, error) {
+	data, err := c.fetchBlob(ctx, repository, digest)
+	if err != nil {
+		return nil, err
+	}
+
+	var config ImageConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("解析config失败: %v", err)
+	}
+	return &config, nil
+}
+
+// v1Images 解析schema1 manifest中的history，第一个条目为最新的层
+func (m *SchemaV1Manifest) v1Images() []V1Image {
+	var images []V1Image
+	for _, h := range m.History {
+		var img V1Image
+		if err := json.Unmarshal([]byte(h.V1Compatibility), &img); err == nil {
+			images = append(images, img)
+		}
+	}
+	return images
+}
+
+// formatCreated 将config中的创建时间转换为 TimeFormat 格式
+func formatCreated(created string) (string, bool) {
+	if created == "" {
+		return "", false
+	}
+	// 尝试解析时间戳
+	if t, err := time.Parse(time.RFC3339, created); err == nil {
+		return t.Format(TimeFormat), true
+	}
+	// 如果RFC3339格式失败，尝试其他格式
+	if t, err := time.Parse("2006-01-02T15:04:05Z", created); err == nil {
+		return t.Format(TimeFormat), true
+	}
+	// 如果已经是本地时间格式，直接返回
+	if len(created) >= 19 {
+		return created[:19], true
+	}
+	return "", false
+}
@@ -0,0 +1,168 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// defaultPageSize 每页请求的条目数量，distribution默认每页返回100条
+const defaultPageSize = 100
+
+// PageIterator 分页遍历 /v2/_catalog 和 /v2/<repo>/tags/list
+// 通过响应中的 Link: <...>; rel="next" 头获取下一页地址
+type PageIterator struct {
+	ctx    context.Context
+	client *Client
+	next   string
+	err    error
+	items  []string
+}
+
+// newPageIterator 创建分页迭代器
+// n 为每页数量（<=0 时使用默认值），last 为上一页的最后一个条目（用于从指定位置继续）
+func (c *Client) newPageIterator(ctx context.Context, path string, n int, last string) *PageIterator {
+	if n <= 0 {
+		n = defaultPageSize
+	}
+
+	query := url.Values{}
+	query.Set("n", strconv.Itoa(n))
+	if last != "" {
+		query.Set("last", last)
+	}
+
+	return &PageIterator{
+		ctx:    ctx,
+		client: c,
+		next:   c.endpoint(ctx, path) + "?" + query.Encode(),
+	}
+}
+
+// CatalogPages 返回遍历仓库目录的分页迭代器
+func (c *Client) CatalogPages(ctx context.Context, n int, last string) *PageIterator {
+	return c.newPageIterator(ctx, "/v2/_catalog", n, last)
+}
+
+// TagPages 返回遍历仓库标签的分页迭代器
+func (c *Client) TagPages(ctx context.Context, repository string, n int, last string) *PageIterator {
+	return c.newPageIterator(ctx, fmt.Sprintf("/v2/%s/tags/list", repository), n, last)
+}
+
+// Next 获取下一页，没有更多页面或出错时返回false
+func (it *PageIterator) Next() bool {
+	if it.err != nil || it.next == "" {
+		return false
+	}
+
+	items, next, err := it.client.fetchPage(it.ctx, it.next)
+	if err != nil {
+		it.err = err
+		return false
+	}
+
+	it.items = items
+	it.next = next
+	return true
+}
+
+// Items 返回当前页的条目
+func (it *PageIterator) Items() []string {
+	return it.items
+}
+
+// Err 返回遍历过程中遇到的错误
+func (it *PageIterator) Err() error {
+	return it.err
+}
+
+// All 遍历剩余的所有页面并返回全部条目
+func (it *PageIterator) All() ([]string, error) {
+	var all []string
+	for it.Next() {
+		all = append(all, it.Items()...)
+	}
+	return all, it.Err()
+}
+
+// fetchPage 获取单页数据，返回条目和下一页的完整地址
+func (c *Client) fetchPage(ctx context.Context, pageURL string) ([]string, string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", pageURL, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("创建请求失败: %v", err)
+	}
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("API请求失败: %w", newResponseError(resp))
+	}
+
+	// _catalog 返回 repositories 字段，tags/list 返回 tags 字段
+	var page struct {
+		Repositories []string `json:"repositories"`
+		Tags         []string `json:"tags"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return nil, "", fmt.Errorf("解析响应失败: %v", err)
+	}
+
+	items := page.Repositories
+	if items == nil {
+		items = page.Tags
+	}
+
+	next, err := nextPageURL(resp.Request.URL, resp.Header.Values("Link"))
+	if err != nil {
+		return nil, "", err
+	}
+	return items, next, nil
+}
+
+// nextPageURL 返回下一页的完整地址，Link 中的相对地址相对于当前页解析，没有下一页时返回空字符串
+func nextPageURL(current *url.URL, headers []string) (string, error) {
+	link := nextLink(headers)
+	if link == "" {
+		return "", nil
+	}
+	next, err := current.Parse(link)
+	if err != nil {
+		return "", fmt.Errorf("无效的分页链接: %v", err)
+	}
+	return next.String(), nil
+}
+
+// nextLink 从 Link 头中找出 rel="next" 的地址
+// 格式：</v2/_catalog?last=foo&n=100>; rel="next"
+func nextLink(headers []string) string {
+	for _, header := range headers {
+		for _, link := range strings.Split(header, ",") {
+			parts := strings.Split(link, ";")
+			target := strings.TrimSpace(parts[0])
+			if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+				continue
+			}
+
+			for _, param := range parts[1:] {
+				key, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+				if !ok || !strings.EqualFold(key, "rel") {
+					continue
+				}
+				for _, rel := range strings.Fields(strings.Trim(value, "\"")) {
+					if strings.EqualFold(rel, "next") {
+						return target[1 : len(target)-1]
+					}
+				}
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,50 @@
+package registry
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestNextPageURL(t *testing.T) {
+	current, err := url.Parse("https://registry.example.com/v2/_catalog?n=100")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		headers []string
+		want    string
+	}{
+		{nil, ""},
+		{[]string{""}, ""},
+		// 相对地址按当前页解析
+		{[]string{`</v2/_catalog?last=php&n=100>; rel="next"`}, "https://registry.example.com/v2/_catalog?last=php&n=100"},
+		{[]string{`<_catalog?last=php&n=100>; rel="next"`}, "https://registry.example.com/v2/_catalog?last=php&n=100"},
+		{[]string{`<https://mirror.example.com/v2/_catalog?last=php>; rel="next"`}, "https://mirror.example.com/v2/_catalog?last=php"},
+		// rel 不带引号、大小写不同或包含多个值
+		{[]string{`</v2/_catalog?last=a>; rel=next`}, "https://registry.example.com/v2/_catalog?last=a"},
+		{[]string{`</v2/_catalog?last=a>; REL="Next"`}, "https://registry.example.com/v2/_catalog?last=a"},
+		{[]string{`</v2/_catalog?last=a>; rel="last next"`}, "https://registry.example.com/v2/_catalog?last=a"},
+		// 一个头中有多个链接，或者有多个 Link 头
+		{[]string{`</v2/_catalog?n=100>; rel="first", </v2/_catalog?last=b>; rel="next"`}, "https://registry.example.com/v2/_catalog?last=b"},
+		{[]string{`</v2/_catalog?n=100>; rel="first"`, `</v2/_catalog?last=c>; rel="next"`}, "https://registry.example.com/v2/_catalog?last=c"},
+		// 没有 rel="next" 或地址格式错误
+		{[]string{`</v2/_catalog?n=100>; rel="prev"`}, ""},
+		{[]string{`/v2/_catalog?last=a; rel="next"`}, ""},
+		{[]string{`</v2/_catalog?last=a>`}, ""},
+	}
+	for _, tt := range tests {
+		got, err := nextPageURL(current, tt.headers)
+		if err != nil {
+			t.Errorf("nextPageURL(%q) 返回错误: %v", tt.headers, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("nextPageURL(%q) = %q，期望 %q", tt.headers, got, tt.want)
+		}
+	}
+
+	if _, err := nextPageURL(current, []string{`<http://[::1>; rel="next"`}); err == nil {
+		t.Errorf("无效的分页链接期望返回错误")
+	}
+}
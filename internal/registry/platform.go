@@ -0,0 +1,177 @@
+package registry
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// platformComponent 平台各部分允许的字符，与containerd一致
+var platformComponent = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// knownOS 和 knownArch 用于判断只有一段的平台是操作系统还是架构
+var knownOS = map[string]bool{
+	"aix": true, "android": true, "darwin": true, "dragonfly": true, "freebsd": true,
+	"hurd": true, "illumos": true, "ios": true, "js": true, "linux": true, "nacl": true,
+	"netbsd": true, "openbsd": true, "plan9": true, "solaris": true, "windows": true, "zos": true,
+}
+
+var knownArch = map[string]bool{
+	"386": true, "amd64": true, "amd64p32": true, "arm": true, "armbe": true, "arm64": true,
+	"arm64be": true, "ppc64": true, "ppc64le": true, "loong64": true, "mips": true, "mipsle": true,
+	"mips64": true, "mips64le": true, "mips64p32": true, "mips64p32le": true, "ppc": true,
+	"riscv": true, "riscv64": true, "s390": true, "s390x": true, "sparc": true, "sparc64": true,
+	"wasm": true,
+}
+
+// ParsePlatform 解析 os、arch、os/arch 或 os/arch/variant 形式的平台
+// 只有一段时根据名称判断是操作系统还是架构（如 linux、arm64、aarch64）；
+// 返回的平台已经规范化，未指定的部分为空
+func ParsePlatform(s string) (Platform, error) {
+	parts := strings.Split(strings.TrimSpace(s), "/")
+	for _, part := range parts {
+		if !platformComponent.MatchString(part) {
+			return Platform{}, fmt.Errorf("无效的平台 %q: 格式应为 os[/arch[/variant]]", s)
+		}
+	}
+
+	var p Platform
+	switch len(parts) {
+	case 1:
+		name := strings.ToLower(parts[0])
+		if knownOS[normalizeOS(name)] {
+			p.OS = name
+		} else {
+			p.Architecture = name
+		}
+	case 2:
+		p.OS, p.Architecture = parts[0], parts[1]
+	case 3:
+		p.OS, p.Architecture, p.Variant = parts[0], parts[1], parts[2]
+	default:
+		return Platform{}, fmt.Errorf("无效的平台 %q: 格式应为 os[/arch[/variant]]", s)
+	}
+
+	if p.Architecture != "" {
+		arch, _ := normalizeArch(p.Architecture, "")
+		if p.OS == "" && !knownArch[arch] {
+			return Platform{}, fmt.Errorf("未知的操作系统或架构 %q", s)
+		}
+	}
+	return p.Normalize(), nil
+}
+
+// Normalize 按containerd的规则规范化平台：统一大小写和别名（如 aarch64 -> arm64、x86_64 -> amd64），
+// 并去掉默认的变体（arm64 的 v8、amd64 的 v1），arm 未指定变体时视为 v7
+func (p Platform) Normalize() Platform {
+	p.OS = normalizeOS(p.OS)
+	if p.Architecture != "" {
+		p.Architecture, p.Variant = normalizeArch(p.Architecture, p.Variant)
+	}
+	return p
+}
+
+// normalizeOS 规范化操作系统名称
+func normalizeOS(os string) string {
+	os = strings.ToLower(os)
+	if os == "macos" {
+		return "darwin"
+	}
+	return os
+}
+
+// normalizeArch 规范化架构和变体，规则与containerd的 platforms.Normalize 一致
+func normalizeArch(arch, variant string) (string, string) {
+	arch, variant = strings.ToLower(arch), strings.ToLower(variant)
+	switch arch {
+	case "i386":
+		arch, variant = "386", ""
+	case "x86_64", "x86-64", "amd64":
+		arch = "amd64"
+		if variant == "v1" {
+			variant = ""
+		}
+	case "aarch64", "arm64":
+		arch = "arm64"
+		switch variant {
+		case "8", "v8", "v8.0":
+			variant = ""
+		case "9", "9.0", "v9.0":
+			variant = "v9"
+		}
+	case "armhf":
+		arch, variant = "arm", "v7"
+	case "armel":
+		arch, variant = "arm", "v6"
+	case "arm":
+		switch variant {
+		case "", "7":
+			variant = "v7"
+		case "5", "6", "8":
+			variant = "v" + variant
+		}
+	}
+	return arch, variant
+}
+
+// PlatformMatcher 匹配一个或多个平台过滤条件，满足任意一个即匹配
+type PlatformMatcher []Platform
+
+// ParsePlatforms 解析逗号分隔的多个平台，如 linux/amd64,linux/arm64
+func ParsePlatforms(s string) (PlatformMatcher, error) {
+	var matcher PlatformMatcher
+	for _, item := range strings.Split(s, ",") {
+		if strings.TrimSpace(item) == "" {
+			continue
+		}
+		p, err := ParsePlatform(item)
+		if err != nil {
+			return nil, err
+		}
+		matcher = append(matcher, p)
+	}
+	if len(matcher) == 0 {
+		return nil, fmt.Errorf("平台不能为空")
+	}
+	return matcher, nil
+}
+
+// Match 判断平台是否满足任意一个过滤条件
+// 过滤条件中未指定的操作系统或架构匹配任意值；指定了架构时变体按规范化后的值精确比较，
+// 因此 linux/arm 只匹配 arm/v7，linux/arm64 与 linux/arm64/v8 等价
+func (m PlatformMatcher) Match(p Platform) bool {
+	p = p.Normalize()
+	for _, filter := range m {
+		if filter.OS != "" && filter.OS != p.OS {
+			continue
+		}
+		if filter.Architecture != "" && (filter.Architecture != p.Architecture || filter.Variant != p.Variant) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// matchesPlatform 检查 os/arch[/variant] 形式的镜像平台是否匹配平台过滤条件
+// 无法解析的过滤条件或镜像平台（如 unknown）视为不匹配
+func matchesPlatform(imgPlatform, filter string) bool {
+	matcher, err := ParsePlatforms(filter)
+	if err != nil {
+		return false
+	}
+	p, err := ParsePlatform(imgPlatform)
+	if err != nil {
+		return false
+	}
+	return matcher.Match(p)
+}
+
+// validatePlatformFilter 检查平台过滤条件的格式，空字符串表示不过滤
+func validatePlatformFilter(platform string) error {
+	if platform == "" {
+		return nil
+	}
+	_, err := ParsePlatforms(platform)
+	return err
+}
@@ -0,0 +1,96 @@
+package registry
+
+import "testing"
+
+func TestParsePlatform(t *testing.T) {
+	tests := []struct {
+		input                     string
+		os, architecture, variant string
+	}{
+		{"linux", "linux", "", ""},
+		{"Linux", "linux", "", ""},
+		{"macos", "darwin", "", ""},
+		{"arm64", "", "arm64", ""},
+		{"aarch64", "", "arm64", ""},
+		{"x86_64", "", "amd64", ""},
+		{"i386", "", "386", ""},
+		{"armhf", "", "arm", "v7"},
+		{"armel", "", "arm", "v6"},
+		{"linux/amd64", "linux", "amd64", ""},
+		{"linux/amd64/v1", "linux", "amd64", ""},
+		{"linux/amd64/v3", "linux", "amd64", "v3"},
+		{"linux/arm64/v8", "linux", "arm64", ""},
+		{"linux/aarch64/8", "linux", "arm64", ""},
+		{"linux/arm", "linux", "arm", "v7"},
+		{"linux/arm/6", "linux", "arm", "v6"},
+		{"linux/arm/v5", "linux", "arm", "v5"},
+		{" linux/ARM64 ", "linux", "arm64", ""},
+	}
+	for _, tt := range tests {
+		p, err := ParsePlatform(tt.input)
+		if err != nil {
+			t.Errorf("ParsePlatform(%q) 返回错误: %v", tt.input, err)
+			continue
+		}
+		if p.OS != tt.os || p.Architecture != tt.architecture || p.Variant != tt.variant {
+			t.Errorf("ParsePlatform(%q) = %q/%q/%q，期望 %q/%q/%q",
+				tt.input, p.OS, p.Architecture, p.Variant, tt.os, tt.architecture, tt.variant)
+		}
+	}
+}
+
+func TestParsePlatformInvalid(t *testing.T) {
+	for _, input := range []string{"", "foo", "linux/", "/amd64", "linux/amd64/v8/x", "linux/amd 64", "linux:amd64"} {
+		if p, err := ParsePlatform(input); err == nil {
+			t.Errorf("ParsePlatform(%q) = %v，期望返回错误", input, p)
+		}
+	}
+}
+
+func TestParsePlatforms(t *testing.T) {
+	m, err := ParsePlatforms("linux/amd64, arm64,,")
+	if err != nil {
+		t.Fatalf("ParsePlatforms 返回错误: %v", err)
+	}
+	if len(m) != 2 {
+		t.Fatalf("ParsePlatforms 返回 %d 个平台，期望 2 个", len(m))
+	}
+
+	for _, input := range []string{"", " , ", "linux,foo"} {
+		if _, err := ParsePlatforms(input); err == nil {
+			t.Errorf("ParsePlatforms(%q) 期望返回错误", input)
+		}
+	}
+}
+
+func TestMatchesPlatform(t *testing.T) {
+	tests := []struct {
+		image, filter string
+		want          bool
+	}{
+		{"linux/amd64", "linux/amd64", true},
+		{"linux/amd64", "amd64", true},
+		{"linux/amd64", "x86_64", true},
+		{"linux/amd64", "linux", true},
+		{"linux/amd64", "windows", false},
+		{"linux/amd64", "arm64", false},
+		{"linux/amd64", "arm64,amd64", true},
+		{"linux/arm64/v8", "linux/arm64", true},
+		{"linux/arm64", "linux/arm64/v8", true},
+		{"linux/arm64", "aarch64", true},
+		{"linux/arm64", "arm", false},
+		{"linux/arm/v7", "arm", true},
+		{"linux/arm/v7", "linux/arm/7", true},
+		{"linux/arm", "linux/arm/v7", true},
+		{"linux/arm/v6", "linux/arm", false},
+		{"linux/arm/v6", "linux/arm/v6", true},
+		{"linux/arm/v7", "linux/arm/v6", false},
+		{"unknown", "linux", false},
+		{"linux/amd64", "foo", false},
+	}
+	for _, tt := range tests {
+		if got := matchesPlatform(tt.image, tt.filter); got != tt.want {
+			t.Errorf("matchesPlatform(%q, %q) = %v，期望 %v", tt.image, tt.filter, got, tt.want)
+		}
+	}
+}
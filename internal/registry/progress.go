@@ -0,0 +1,100 @@
+package registry
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// Reporter 接收客户端的进度和诊断信息
+// registry包自身从不写标准输出，是否显示以及显示到哪里由调用方注入的Reporter决定
+type Reporter interface {
+	// Infof 报告一条诊断信息
+	Infof(format string, args ...interface{})
+	// StartProgress 开始一个共 total 项的进度
+	StartProgress(label string, total int) Progress
+}
+
+// Progress 表示一个进行中的进度，可被多个goroutine同时更新
+type Progress interface {
+	// Increment 完成一项
+	Increment()
+	// Done 结束进度并清除显示
+	Done()
+}
+
+// SetReporter 设置客户端的进度和诊断信息输出，传入nil表示不输出
+func (c *Client) SetReporter(reporter Reporter) {
+	if reporter == nil {
+		reporter = NopReporter{}
+	}
+	c.reporter = reporter
+}
+
+// NopReporter 丢弃所有进度和诊断信息
+type NopReporter struct{}
+
+// Infof 丢弃诊断信息
+func (NopReporter) Infof(format string, args ...interface{}) {}
+
+// StartProgress 返回不显示的进度
+func (NopReporter) StartProgress(label string, total int) Progress { return nopProgress{} }
+
+type nopProgress struct{}
+
+func (nopProgress) Increment() {}
+func (nopProgress) Done()      {}
+
+// terminalReporter 在终端上显示进度条和诊断信息
+type terminalReporter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewTerminalReporter 创建向 w（通常是终端上的标准错误）输出进度条的Reporter
+func NewTerminalReporter(w io.Writer) Reporter {
+	return &terminalReporter{w: w}
+}
+
+// Infof 输出一行诊断信息
+func (r *terminalReporter) Infof(format string, args ...interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	fmt.Fprintf(r.w, format+"\n", args...)
+}
+
+// StartProgress 开始显示进度条
+func (r *terminalReporter) StartProgress(label string, total int) Progress {
+	return &progressBar{reporter: r, label: label, total: total}
+}
+
+// progressBar 终端上的进度条
+type progressBar struct {
+	reporter *terminalReporter
+	label    string
+	total    int
+	done     int
+}
+
+// Increment 完成一项并刷新进度条
+func (p *progressBar) Increment() {
+	p.reporter.mu.Lock()
+	defer p.reporter.mu.Unlock()
+
+	p.done++
+	progress := float64(p.done) / float64(p.total)
+	barWidth := 30
+	filled := int(progress * float64(barWidth))
+	bar := strings.Repeat("█", filled) + strings.Repeat("░", barWidth-filled)
+	fmt.Fprintf(p.reporter.w, "\r%s: %s %d/%d", p.label, bar, p.done, p.total)
+}
+
+// Done 清除进度条
+func (p *progressBar) Done() {
+	p.reporter.mu.Lock()
+	defer p.reporter.mu.Unlock()
+
+	fmt.Fprint(p.reporter.w, "\r"+strings.Repeat(" ", 80)+"\r")
+}
@@ -0,0 +1,109 @@
+package registry
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// matcher 匹配仓库名或标签，由 Glob、Constraint 和正则表达式实现
+type matcher interface {
+	Match(s string) bool
+}
+
+// regexMatcher 使用正则表达式匹配，与 grep 一样只需部分匹配，需要完整匹配时使用 ^ 和 $
+type regexMatcher struct {
+	re *regexp.Regexp
+}
+
+func (m regexMatcher) Match(s string) bool {
+	return m.re.MatchString(s)
+}
+
+// constraintOperators 标签部分以这些字符开头时按版本约束解析，如 php:^8.1、php:>=8.1
+const constraintOperators = "^~<>=!"
+
+// searchQuery 解析后的搜索条件，格式为 repository[:tag]
+type searchQuery struct {
+	repository matcher
+	// tag 为nil时不按标签过滤
+	tag matcher
+}
+
+// parseSearchQuery 解析搜索条件
+// 默认两部分都是通配符模式，没有通配符的部分按完整名称匹配；opts.Regex 为true时按正则表达式匹配；
+// 标签部分以版本约束的运算符开头或指定了 opts.Semver 时，按版本约束匹配标签
+func parseSearchQuery(query string, opts SearchOptions) (*searchQuery, error) {
+	repoPattern, tagPattern := query, ""
+	if opts.Regex {
+		repoPattern, tagPattern = splitRegexQuery(query)
+	} else {
+		repoPattern, tagPattern, _ = strings.Cut(query, ":")
+	}
+
+	q := &searchQuery{}
+	var err error
+	if q.repository, err = compileQueryPattern(repoPattern, opts); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case opts.Semver != "":
+		if tagPattern != "" {
+			return nil, fmt.Errorf("使用 --semver 时查询中不能再指定标签")
+		}
+		if q.tag, err = ParseConstraint(opts.Semver); err != nil {
+			return nil, err
+		}
+	case tagPattern != "" && !opts.Regex && strings.ContainsRune(constraintOperators, rune(tagPattern[0])):
+		if q.tag, err = ParseConstraint(tagPattern); err != nil {
+			return nil, err
+		}
+	case tagPattern != "":
+		if q.tag, err = compileQueryPattern(tagPattern, opts); err != nil {
+			return nil, err
+		}
+	}
+	return q, nil
+}
+
+// compileQueryPattern 按选项将查询的一部分编译为通配符或正则表达式
+func compileQueryPattern(pattern string, opts SearchOptions) (matcher, error) {
+	if !opts.Regex {
+		return CompileGlob(pattern, opts.IgnoreCase)
+	}
+	if opts.IgnoreCase {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("无效的正则表达式: %v", err)
+	}
+	return regexMatcher{re: re}, nil
+}
+
+// splitRegexQuery 在第一个不属于正则表达式语法的冒号处拆分查询
+// 跳过转义字符、字符集合和括号内的冒号，如 (?i:php):8\.[12]
+func splitRegexQuery(query string) (string, string) {
+	depth := 0
+	inClass := false
+	for i := 0; i < len(query); i++ {
+		switch ch := query[i]; {
+		case ch == '\\':
+			i++
+		case inClass:
+			if ch == ']' {
+				inClass = false
+			}
+		case ch == '[':
+			inClass = true
+		case ch == '(':
+			depth++
+		case ch == ')':
+			depth--
+		case ch == ':' && depth == 0:
+			return query[:i], query[i+1:]
+		}
+	}
+	return query, ""
+}
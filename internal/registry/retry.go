@@ -0,0 +1,113 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+const (
+	// maxRetries 请求失败后的最大重试次数
+	maxRetries = 3
+	// retryBaseDelay 第一次重试前的等待时间，之后每次加倍
+	retryBaseDelay = 500 * time.Millisecond
+	// maxRetryDelay 两次重试之间的最长等待时间（包括 Retry-After 指定的时间）
+	maxRetryDelay = 30 * time.Second
+)
+
+// shouldRetry 判断请求是否应该重试：网络暂时性错误、429和5xx响应
+func shouldRetry(req *http.Request, resp *http.Response, err error) bool {
+	// 请求已被取消或超时，不再重试
+	if req.Context().Err() != nil {
+		return false
+	}
+	// 请求体无法重新读取时不能重试
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+
+	if err != nil {
+		return isTransientError(err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// isTransientError 判断网络错误是否为暂时性的（超时、连接被重置、连接意外关闭）
+// 连接被拒绝通常是地址或端口错误、服务未启动，重试没有意义
+func isTransientError(err error) bool {
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// retryDelay 返回第 attempt 次重试前的等待时间
+// 优先使用响应中的 Retry-After，否则使用带随机抖动的指数退避
+func retryDelay(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if delay, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			return min(delay, maxRetryDelay)
+		}
+	}
+
+	delay := retryBaseDelay << attempt
+	delay += time.Duration(rand.Int64N(int64(delay) / 2))
+	return min(delay, maxRetryDelay)
+}
+
+// parseRetryAfter 解析 Retry-After 头，支持秒数和HTTP日期两种格式
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return max(time.Until(t), 0), true
+	}
+	return 0, false
+}
+
+// rewindRequest 复制请求用于重试，重新获取请求体
+func rewindRequest(req *http.Request) (*http.Request, error) {
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		retry.Body = body
+	}
+	return retry, nil
+}
+
+// sleepContext 等待指定时间，context被取消时提前返回
+func sleepContext(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,126 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{"", 0, false},
+		{"0", 0, true},
+		{"120", 120 * time.Second, true},
+		{"-1", 0, false},
+		{"1.5", 0, false},
+		{"soon", 0, false},
+		// 过去的日期表示立即重试
+		{"Wed, 21 Oct 2015 07:28:00 GMT", 0, true},
+	}
+	for _, tt := range tests {
+		got, ok := parseRetryAfter(tt.value)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseRetryAfter(%q) = %v, %v，期望 %v, %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+
+	date := time.Now().Add(10 * time.Second).UTC().Format(http.TimeFormat)
+	got, ok := parseRetryAfter(date)
+	if !ok || got <= 8*time.Second || got > 10*time.Second {
+		t.Errorf("parseRetryAfter(%q) = %v, %v，期望约10秒", date, got, ok)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	// 指数退避，抖动不超过本次等待时间的一半
+	for attempt := 0; attempt < maxRetries; attempt++ {
+		base := retryBaseDelay << attempt
+		for i := 0; i < 20; i++ {
+			if delay := retryDelay(attempt, nil); delay < base || delay >= base+base/2 {
+				t.Errorf("retryDelay(%d) = %v，期望在 [%v, %v) 之间", attempt, delay, base, base+base/2)
+			}
+		}
+	}
+	if delay := retryDelay(10, nil); delay != maxRetryDelay {
+		t.Errorf("retryDelay(10) = %v，期望 %v", delay, maxRetryDelay)
+	}
+
+	// Retry-After 优先，但不超过最长等待时间
+	tests := []struct {
+		retryAfter string
+		want       time.Duration
+	}{
+		{"3", 3 * time.Second},
+		{"0", 0},
+		{"3600", maxRetryDelay},
+	}
+	for _, tt := range tests {
+		resp := &http.Response{Header: http.Header{"Retry-After": {tt.retryAfter}}}
+		if delay := retryDelay(0, resp); delay != tt.want {
+			t.Errorf("Retry-After: %s 时 retryDelay = %v，期望 %v", tt.retryAfter, delay, tt.want)
+		}
+	}
+}
+
+func TestShouldRetry(t *testing.T) {
+	get, _ := http.NewRequest("GET", "https://registry.example.com/v2/", nil)
+
+	for status, want := range map[int]bool{
+		http.StatusOK:                  false,
+		http.StatusUnauthorized:        false,
+		http.StatusNotFound:            false,
+		http.StatusTooManyRequests:     true,
+		http.StatusInternalServerError: true,
+		http.StatusNotImplemented:      false,
+		http.StatusBadGateway:          true,
+		http.StatusServiceUnavailable:  true,
+		http.StatusGatewayTimeout:      true,
+	} {
+		if got := shouldRetry(get, &http.Response{StatusCode: status}, nil); got != want {
+			t.Errorf("状态码 %d 时 shouldRetry = %v，期望 %v", status, got, want)
+		}
+	}
+
+	errorTests := []struct {
+		err  error
+		want bool
+	}{
+		{syscall.ECONNRESET, true},
+		{fmt.Errorf("read: %w", syscall.ECONNRESET), true},
+		{io.EOF, true},
+		{io.ErrUnexpectedEOF, true},
+		// 连接被拒绝说明服务未启动或地址错误，不重试
+		{syscall.ECONNREFUSED, false},
+		{fmt.Errorf("dial tcp: %w", syscall.ECONNREFUSED), false},
+		{context.DeadlineExceeded, true},
+		{errors.New("x509: certificate signed by unknown authority"), false},
+	}
+	for _, tt := range errorTests {
+		if got := shouldRetry(get, nil, tt.err); got != tt.want {
+			t.Errorf("错误 %v 时 shouldRetry = %v，期望 %v", tt.err, got, tt.want)
+		}
+	}
+
+	// 已取消的请求和无法重新读取请求体的请求不重试
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	canceled, _ := http.NewRequestWithContext(ctx, "GET", "https://registry.example.com/v2/", nil)
+	if shouldRetry(canceled, &http.Response{StatusCode: http.StatusServiceUnavailable}, nil) {
+		t.Errorf("已取消的请求不应该重试")
+	}
+	post, _ := http.NewRequest("POST", "https://registry.example.com/token", strings.NewReader("a=b"))
+	post.GetBody = nil
+	if shouldRetry(post, &http.Response{StatusCode: http.StatusServiceUnavailable}, nil) {
+		t.Errorf("无法重新读取请求体的请求不应该重试")
+	}
+}
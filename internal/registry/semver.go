@@ -0,0 +1,219 @@
+package registry
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// versionPattern 匹配标签开头的版本号，如 8、8.1、v8.1.22，之后可以跟 -alpine、-fpm 等后缀
+var versionPattern = regexp.MustCompile(`^v?(\d+)(?:\.(\d+))?(?:\.(\d+))?(?:[-+_.](.*))?$`)
+
+// Version 从镜像标签中解析出的版本号
+// 标签中的后缀（如 -alpine、-fpm-bookworm）不参与版本比较，只在版本相同时用于排序
+type Version struct {
+	Major, Minor, Patch int
+	// Suffix 版本号之后的部分，不含分隔符
+	Suffix string
+	// parts 标签中给出的版本号段数（1-3），用于约束中的部分版本号
+	parts int
+}
+
+// ParseVersion 将标签解析为版本号，如 8.1、8.1.22-alpine、v1.2.3
+func ParseVersion(tag string) (Version, bool) {
+	m := versionPattern.FindStringSubmatch(tag)
+	if m == nil {
+		return Version{}, false
+	}
+
+	v := Version{Suffix: m[4], parts: 1}
+	v.Major, _ = strconv.Atoi(m[1])
+	if m[2] != "" {
+		v.Minor, _ = strconv.Atoi(m[2])
+		v.parts = 2
+	}
+	if m[3] != "" {
+		v.Patch, _ = strconv.Atoi(m[3])
+		v.parts = 3
+	}
+	return v, true
+}
+
+// String 返回 major.minor.patch 形式的版本号，有后缀时附加在后面
+func (v Version) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Suffix != "" {
+		s += "-" + v.Suffix
+	}
+	return s
+}
+
+// Compare 比较两个版本号（忽略后缀），返回 -1、0 或 1
+func (v Version) Compare(other Version) int {
+	for _, d := range [][2]int{{v.Major, other.Major}, {v.Minor, other.Minor}, {v.Patch, other.Patch}} {
+		if d[0] != d[1] {
+			if d[0] < d[1] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// bump 返回部分版本号的下一个版本，如 8.1 -> 8.2.0、8 -> 9.0.0
+func (v Version) bump() Version {
+	switch v.parts {
+	case 1:
+		return Version{Major: v.Major + 1, parts: 3}
+	case 2:
+		return Version{Major: v.Major, Minor: v.Minor + 1, parts: 3}
+	}
+	return Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch + 1, parts: 3}
+}
+
+// versionRange 半开区间 [min, max)，为nil的一端不限制；exclude 为true时取区间以外的版本
+type versionRange struct {
+	min, max *Version
+	exclude  bool
+}
+
+func (r versionRange) contains(v Version) bool {
+	in := (r.min == nil || v.Compare(*r.min) >= 0) && (r.max == nil || v.Compare(*r.max) < 0)
+	return in != r.exclude
+}
+
+// Constraint 版本约束，如 ^8.1、~8.1、>=8.1,<8.3、7.4 || >=8.2
+// 逗号或空格分隔的条件需要同时满足，|| 分隔的条件组满足任意一组即可
+type Constraint struct {
+	raw    string
+	groups [][]versionRange
+}
+
+// ParseConstraint 解析版本约束
+// 支持的运算符：=、!=、>、>=、<、<=、~（允许补丁版本更新）和 ^（允许不改变最左侧非零版本号的更新）；
+// 部分版本号（也可以写作 8.1.x）表示一个范围，如 8.1 等价于 >=8.1.0,<8.2.0，>8.1 等价于 >=8.2.0
+func ParseConstraint(s string) (*Constraint, error) {
+	c := &Constraint{raw: s}
+	for _, group := range strings.Split(s, "||") {
+		var ranges []versionRange
+		for _, item := range strings.FieldsFunc(group, func(r rune) bool { return r == ',' || r == ' ' }) {
+			r, err := parseVersionRange(item)
+			if err != nil {
+				return nil, fmt.Errorf("无效的版本约束 %q: %v", s, err)
+			}
+			ranges = append(ranges, r)
+		}
+		if len(ranges) == 0 {
+			return nil, fmt.Errorf("无效的版本约束 %q: 条件不能为空", s)
+		}
+		c.groups = append(c.groups, ranges)
+	}
+	return c, nil
+}
+
+// parseVersionRange 将单个条件转换为版本区间
+func parseVersionRange(item string) (versionRange, error) {
+	op := item[:len(item)-len(strings.TrimLeft(item, "=<>!~^"))]
+	// 8.x、8.1.* 等同于部分版本号 8、8.1
+	version := item[len(op):]
+	for _, wildcard := range []string{".x", ".X", ".*"} {
+		for strings.HasSuffix(version, wildcard) {
+			version = strings.TrimSuffix(version, wildcard)
+		}
+	}
+	v, ok := ParseVersion(version)
+	if !ok || v.Suffix != "" {
+		return versionRange{}, fmt.Errorf("无法解析版本号 %q", item[len(op):])
+	}
+
+	exact := v
+	exact.parts = 3
+	next := v.bump()
+	switch op {
+	case "", "=", "==":
+		return versionRange{min: &exact, max: &next}, nil
+	case "!=":
+		return versionRange{min: &exact, max: &next, exclude: true}, nil
+	case ">":
+		return versionRange{min: &next}, nil
+	case ">=":
+		return versionRange{min: &exact}, nil
+	case "<":
+		return versionRange{max: &exact}, nil
+	case "<=":
+		return versionRange{max: &next}, nil
+	case "~":
+		// ~8.1.2 -> <8.2.0，~8.1 -> <8.2.0，~8 -> <9.0.0
+		upper := Version{Major: v.Major, Minor: v.Minor, parts: 2}
+		if v.parts == 1 {
+			upper.parts = 1
+		}
+		upper = upper.bump()
+		return versionRange{min: &exact, max: &upper}, nil
+	case "^":
+		// ^8.1 -> <9.0.0，^0.2 -> <0.3.0，^0.0.3 -> <0.0.4
+		var upper Version
+		switch {
+		case v.Major != 0 || v.parts == 1:
+			upper = Version{Major: v.Major, parts: 1}.bump()
+		case v.Minor != 0 || v.parts == 2:
+			upper = Version{Minor: v.Minor, parts: 2}.bump()
+		default:
+			upper = exact.bump()
+		}
+		return versionRange{min: &exact, max: &upper}, nil
+	}
+	return versionRange{}, fmt.Errorf("不支持的运算符 %q", op)
+}
+
+// Match 判断标签是否满足约束，无法解析为版本号的标签（如 latest）不满足任何约束
+func (c *Constraint) Match(tag string) bool {
+	v, ok := ParseVersion(tag)
+	if !ok {
+		return false
+	}
+	for _, group := range c.groups {
+		matched := true
+		for _, r := range group {
+			if !r.contains(v) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// String 返回原始的约束
+func (c *Constraint) String() string {
+	return c.raw
+}
+
+// SortTagsByVersion 按版本号从新到旧排序标签
+// 版本相同时没有后缀的标签在前，其余按后缀排序；无法解析为版本号的标签排在最后并保持原有顺序
+func SortTagsByVersion(tags []string) {
+	sort.SliceStable(tags, func(i, j int) bool {
+		vi, oki := ParseVersion(tags[i])
+		vj, okj := ParseVersion(tags[j])
+		if !oki || !okj {
+			return oki && !okj
+		}
+		if cmp := vi.Compare(vj); cmp != 0 {
+			return cmp > 0
+		}
+		// 8.1 与 8.1.0 相同时，更具体的版本号在前
+		if vi.parts != vj.parts {
+			return vi.parts > vj.parts
+		}
+		if (vi.Suffix == "") != (vj.Suffix == "") {
+			return vi.Suffix == ""
+		}
+		return vi.Suffix < vj.Suffix
+	})
+}
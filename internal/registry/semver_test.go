@@ -0,0 +1,118 @@
+package registry
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		tag                 string
+		major, minor, patch int
+		suffix              string
+		parts               int
+	}{
+		{"8", 8, 0, 0, "", 1},
+		{"8.1", 8, 1, 0, "", 2},
+		{"8.1.22", 8, 1, 22, "", 3},
+		{"v1.2.3", 1, 2, 3, "", 3},
+		{"8.1-fpm", 8, 1, 0, "fpm", 2},
+		{"8.1.22-fpm-bookworm", 8, 1, 22, "fpm-bookworm", 3},
+		{"1.25_alpine", 1, 25, 0, "alpine", 2},
+		{"3.0.0+build.1", 3, 0, 0, "build.1", 3},
+		{"1.2.3.4", 1, 2, 3, "4", 3},
+	}
+	for _, tt := range tests {
+		v, ok := ParseVersion(tt.tag)
+		if !ok {
+			t.Errorf("ParseVersion(%q) 解析失败", tt.tag)
+			continue
+		}
+		if v.Major != tt.major || v.Minor != tt.minor || v.Patch != tt.patch || v.Suffix != tt.suffix || v.parts != tt.parts {
+			t.Errorf("ParseVersion(%q) = %d.%d.%d %q (%d段)，期望 %d.%d.%d %q (%d段)", tt.tag,
+				v.Major, v.Minor, v.Patch, v.Suffix, v.parts, tt.major, tt.minor, tt.patch, tt.suffix, tt.parts)
+		}
+	}
+
+	for _, tag := range []string{"latest", "alpine", "", "v", "x8.1", "8x"} {
+		if _, ok := ParseVersion(tag); ok {
+			t.Errorf("ParseVersion(%q) 不应该解析为版本号", tag)
+		}
+	}
+}
+
+func TestConstraintMatch(t *testing.T) {
+	tests := []struct {
+		constraint string
+		matches    []string
+		rejects    []string
+	}{
+		// 部分版本号表示范围
+		{"8.1", []string{"8.1", "8.1.0", "8.1.22", "8.1-fpm"}, []string{"8.2", "8.10", "8", "latest"}},
+		{"8", []string{"8", "8.0", "8.3.1"}, []string{"7.4", "9.0"}},
+		{"8.1.x", []string{"8.1.5"}, []string{"8.2.0"}},
+		{"8.*", []string{"8.3"}, []string{"9.0"}},
+		{"=8.1.2", []string{"8.1.2", "v8.1.2-alpine"}, []string{"8.1.3", "8.1"}},
+		{"==8.1.2", []string{"8.1.2"}, []string{"8.1.3"}},
+		{"!=8.1", []string{"8.0.9", "8.2.0"}, []string{"8.1", "8.1.5"}},
+
+		// 比较运算符
+		{">8.1", []string{"8.2", "9"}, []string{"8.1", "8.1.9"}},
+		{">8.1.2", []string{"8.1.3"}, []string{"8.1.2"}},
+		{">=8.1", []string{"8.1", "8.1.0", "10.0"}, []string{"8.0.99"}},
+		{"<8.1", []string{"8.0.99", "7"}, []string{"8.1", "8.1.0"}},
+		{"<=8.1", []string{"8.1.99", "8.0"}, []string{"8.2"}},
+
+		// ~ 允许补丁版本更新，只有主版本号时允许次版本更新
+		{"~8.1.2", []string{"8.1.2", "8.1.9"}, []string{"8.1.1", "8.2.0"}},
+		{"~8.1", []string{"8.1.0", "8.1.9"}, []string{"8.2"}},
+		{"~8", []string{"8.0", "8.9"}, []string{"9.0", "7.9"}},
+
+		// ^ 不改变最左侧的非零版本号
+		{"^8.1", []string{"8.1", "8.9.9"}, []string{"8.0.9", "9.0"}},
+		{"^0.2.3", []string{"0.2.3", "0.2.9"}, []string{"0.3.0", "0.2.2"}},
+		{"^0.2", []string{"0.2.0", "0.2.9"}, []string{"0.3"}},
+		{"^0.0.3", []string{"0.0.3"}, []string{"0.0.4"}},
+		{"^0", []string{"0.0.1", "0.9"}, []string{"1.0"}},
+
+		// 逗号或空格表示同时满足，|| 表示满足任意一组
+		{">=8.1,<8.3", []string{"8.1", "8.2.5"}, []string{"8.0", "8.3"}},
+		{">=8.1 <8.3", []string{"8.2"}, []string{"8.3.0"}},
+		{"7.4 || >=8.2", []string{"7.4.33", "8.2", "9"}, []string{"7.3", "8.1"}},
+		{"~7.4 || 8.x", []string{"7.4.1", "8.3"}, []string{"7.5", "9.0"}},
+	}
+	for _, tt := range tests {
+		c, err := ParseConstraint(tt.constraint)
+		if err != nil {
+			t.Errorf("ParseConstraint(%q) 返回错误: %v", tt.constraint, err)
+			continue
+		}
+		for _, tag := range tt.matches {
+			if !c.Match(tag) {
+				t.Errorf("%q 应该匹配 %q", tt.constraint, tag)
+			}
+		}
+		for _, tag := range tt.rejects {
+			if c.Match(tag) {
+				t.Errorf("%q 不应该匹配 %q", tt.constraint, tag)
+			}
+		}
+	}
+}
+
+func TestParseConstraintInvalid(t *testing.T) {
+	for _, s := range []string{"", "||", "8.1 ||", ">=", "latest", "8.1-alpine", "=>8.1", "<>8", ">=8.1,,x"} {
+		if _, err := ParseConstraint(s); err == nil {
+			t.Errorf("ParseConstraint(%q) 期望返回错误", s)
+		}
+	}
+}
+
+func TestSortTagsByVersion(t *testing.T) {
+	tags := []string{"latest", "8.1-fpm", "7.4", "8.1.0", "8.10", "8.1", "alpine", "8.2-alpine", "8.2", "8.1-cli", "v8.3.0"}
+	SortTagsByVersion(tags)
+	want := []string{"8.10", "v8.3.0", "8.2", "8.2-alpine", "8.1.0", "8.1", "8.1-cli", "8.1-fpm", "7.4", "latest", "alpine"}
+	if !reflect.DeepEqual(tags, want) {
+		t.Errorf("SortTagsByVersion = %v，期望 %v", tags, want)
+	}
+}
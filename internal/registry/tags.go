@@ -0,0 +1,140 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+)
+
+// TagInfo 表示标签在某个平台上的详细信息
+type TagInfo struct {
+	Repository string `json:"repository" yaml:"repository"`
+	Tag        string `json:"tag" yaml:"tag"`
+	Platform   string `json:"platform" yaml:"platform"`
+	Digest     string `json:"digest" yaml:"digest"`
+	Size       string `json:"size" yaml:"size"`
+	Created    string `json:"created" yaml:"created"`
+
+	// sizeBytes 压缩大小，用于排序
+	sizeBytes int64
+}
+
+// ListTags 列出仓库中的标签，每个标签的每个平台各占一行
+// tagPattern 为通配符模式（见 Glob），为空时列出所有标签；platform 为空时列出所有平台
+func (c *Client) ListTags(ctx context.Context, repository, tagPattern, platform string) ([]TagInfo, error) {
+	if err := validatePlatformFilter(platform); err != nil {
+		return nil, err
+	}
+	var glob *Glob
+	if tagPattern != "" {
+		var err error
+		if glob, err = CompileGlob(tagPattern, false); err != nil {
+			return nil, err
+		}
+	}
+
+	// 检查是否有有效的认证信息
+	if !c.HasValidCredentials(ctx) {
+		return nil, fmt.Errorf("未找到有效的认证信息，请先使用 'docker genee login' 登录")
+	}
+
+	tags, err := c.getRepositoryTags(ctx, repository)
+	if err != nil {
+		return nil, fmt.Errorf("获取标签列表失败: %w", err)
+	}
+
+	// 过滤出符合标签模式的标签
+	if glob != nil {
+		var filteredTags []string
+		for _, tag := range tags {
+			if glob.Match(tag) {
+				filteredTags = append(filteredTags, tag)
+			}
+		}
+		tags = filteredTags
+	}
+
+	// 并发获取每个标签的平台详情，结果按标签顺序存放
+	tagRows := make([][]TagInfo, len(tags))
+	bar := c.reporter.StartProgress("进度", len(tags))
+	c.runConcurrently(ctx, len(tags), func(i int) {
+		defer bar.Increment()
+		tagRows[i] = c.getTagPlatformDetails(ctx, repository, tags[i])
+	})
+	bar.Done()
+
+	// 操作被取消或超时时返回错误，不输出不完整的结果
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var rows []TagInfo
+	for _, tagInfos := range tagRows {
+		for _, info := range tagInfos {
+			if platform != "" && !matchesPlatform(info.Platform, platform) {
+				continue
+			}
+			rows = append(rows, info)
+		}
+	}
+	return rows, nil
+}
+
+// getTagPlatformDetails 获取单个标签在每个平台上的digest、压缩大小和创建时间
+func (c *Client) getTagPlatformDetails(ctx context.Context, repository, tag string) []TagInfo {
+	manifest, err := c.getManifest(ctx, repository, tag)
+	if err != nil {
+		return nil
+	}
+
+	switch {
+	case manifest.Index != nil:
+		// 多平台manifest：逐个获取每个平台的manifest
+		var rows []TagInfo
+		for _, descriptor := range indexPlatformManifests(manifest.Index) {
+			info := TagInfo{
+				Repository: repository,
+				Tag:        tag,
+				Platform:   descriptor.Platform.String(),
+				Digest:     descriptor.Digest,
+			}
+			if platformManifest, err := c.getManifest(ctx, repository, descriptor.Digest); err == nil && platformManifest.Manifest != nil {
+				info.sizeBytes = platformManifest.Manifest.TotalSize()
+				info.Size = FormatSize(info.sizeBytes)
+				info.Created = c.getConfigCreatedTime(ctx, repository, platformManifest.Manifest.Config.Digest)
+			}
+			rows = append(rows, info)
+		}
+		return rows
+	case manifest.Manifest != nil:
+		// 单平台manifest：平台和创建时间都来自config
+		info := TagInfo{
+			Repository: repository,
+			Tag:        tag,
+			Platform:   "unknown",
+			Digest:     manifest.Digest,
+			Size:       FormatSize(manifest.Manifest.TotalSize()),
+			sizeBytes:  manifest.Manifest.TotalSize(),
+		}
+		if config, err := c.getImageConfig(ctx, repository, manifest.Manifest.Config.Digest); err == nil {
+			if config.OS != "" && config.Architecture != "" {
+				info.Platform = config.Platform().String()
+			}
+			info.Created, _ = formatCreated(config.Created)
+		}
+		return []TagInfo{info}
+	case manifest.V1 != nil:
+		// Docker v1格式：没有压缩大小信息
+		info := TagInfo{
+			Repository: repository,
+			Tag:        tag,
+			Platform:   "unknown",
+			Digest:     manifest.Digest,
+			Created:    getV1CreatedTime(manifest.V1),
+		}
+		if images := manifest.V1.v1Images(); len(images) > 0 && images[0].OS != "" && images[0].Architecture != "" {
+			info.Platform = fmt.Sprintf("%s/%s", images[0].OS, images[0].Architecture)
+		}
+		return []TagInfo{info}
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package registry
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// systemCertsDir Docker守护进程读取registry证书的目录
+const systemCertsDir = "/etc/docker/certs.d"
+
+// tlsFiles 通过 --cacert、--cert、--key 指定的TLS文件
+type tlsFiles struct {
+	caCert string
+	cert   string
+	key    string
+}
+
+// SetTLSFiles 设置额外的CA证书和客户端证书，cert 和 key 需要同时指定
+// 返回的错误同样会保存下来，在之后的请求中返回
+func (c *Client) SetTLSFiles(caCert, cert, key string) error {
+	if (cert == "") != (key == "") {
+		c.transportErr = fmt.Errorf("客户端证书和私钥需要同时指定")
+		return c.transportErr
+	}
+	c.tlsFiles = tlsFiles{caCert: caCert, cert: cert, key: key}
+	return c.configureTransport()
+}
+
+// configureTransport 按TLS设置重新创建HTTP传输层
+// 证书加载失败时错误会保存下来，在之后的请求中返回
+func (c *Client) configureTransport() error {
+	config, err := c.tlsConfig()
+	c.transportErr = err
+	if err != nil {
+		return err
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = config
+	c.httpClient.Transport = transport
+	return nil
+}
+
+// tlsConfig 创建访问registry使用的TLS配置
+// 与Docker守护进程一样，从 certs.d/<registry>/ 目录加载 *.crt CA证书和 *.cert/*.key 客户端证书
+func (c *Client) tlsConfig() (*tls.Config, error) {
+	config := &tls.Config{
+		InsecureSkipVerify: c.insecure,
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	customCA := false
+
+	// 显式指定的文件优先
+	if c.tlsFiles.caCert != "" {
+		if err := appendCACert(pool, c.tlsFiles.caCert); err != nil {
+			return nil, err
+		}
+		customCA = true
+	}
+	if c.tlsFiles.cert != "" {
+		cert, err := tls.LoadX509KeyPair(c.tlsFiles.cert, c.tlsFiles.key)
+		if err != nil {
+			return nil, fmt.Errorf("加载客户端证书 %s 失败: %v", c.tlsFiles.cert, err)
+		}
+		config.Certificates = append(config.Certificates, cert)
+	}
+
+	for _, dir := range c.certsDirs() {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+
+		for _, entry := range entries {
+			name := entry.Name()
+			path := filepath.Join(dir, name)
+			switch {
+			case strings.HasSuffix(name, ".crt"):
+				if err := appendCACert(pool, path); err != nil {
+					return nil, err
+				}
+				customCA = true
+			case strings.HasSuffix(name, ".cert"):
+				keyPath := filepath.Join(dir, strings.TrimSuffix(name, ".cert")+".key")
+				if _, err := os.Stat(keyPath); err != nil {
+					return nil, fmt.Errorf("缺少客户端证书 %s 对应的私钥 %s", path, keyPath)
+				}
+				cert, err := tls.LoadX509KeyPair(path, keyPath)
+				if err != nil {
+					return nil, fmt.Errorf("加载客户端证书 %s 失败: %v", path, err)
+				}
+				config.Certificates = append(config.Certificates, cert)
+			case strings.HasSuffix(name, ".key"):
+				certPath := filepath.Join(dir, strings.TrimSuffix(name, ".key")+".cert")
+				if _, err := os.Stat(certPath); err != nil {
+					return nil, fmt.Errorf("缺少私钥 %s 对应的客户端证书 %s", path, certPath)
+				}
+			}
+		}
+	}
+
+	if customCA {
+		config.RootCAs = pool
+	}
+	return config, nil
+}
+
+// certsDirs 返回registry证书所在的目录：/etc/docker/certs.d/<registry>/ 和 <Docker配置目录>/certs.d/<registry>/
+func (c *Client) certsDirs() []string {
+	return []string{
+		filepath.Join(systemCertsDir, c.registryURL),
+		filepath.Join(c.dockerConfigDir, "certs.d", c.registryURL),
+	}
+}
+
+// appendCACert 将PEM格式的CA证书加入证书池
+func appendCACert(pool *x509.CertPool, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("读取CA证书 %s 失败: %v", path, err)
+	}
+	if !pool.AppendCertsFromPEM(data) {
+		return fmt.Errorf("CA证书 %s 中没有有效的PEM证书", path)
+	}
+	return nil
+}